@@ -1,12 +1,25 @@
 package main
 
 import (
+	"context"
 	_ "database/sql"
 	"fmt"
+	nethttp "net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	validator "avitoTask/internal"
 	"avitoTask/internal/auth"
+	"avitoTask/internal/events"
 	"avitoTask/internal/http"
+	"avitoTask/internal/metrics"
+	"avitoTask/internal/outbox"
+	"avitoTask/internal/repository"
+	"avitoTask/internal/repository/memory"
+	postgresRepository "avitoTask/internal/repository/postgres"
+	"avitoTask/internal/webhook"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
@@ -17,22 +30,186 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-type DbConfig struct {
+type ServerConfig struct {
 	ServerAddress string `env:"SERVER_ADDRESS" env-required:"true"`
-	Host          string `env:"POSTGRES_HOST" env-required:"true"`
-	Port          int    `env:"POSTGRES_PORT" env-required:"true"`
-	Dbname        string `env:"POSTGRES_DATABASE" env-required:"true"`
-	User          string `env:"POSTGRES_USERNAME" env-required:"true"`
-	Password      string `env:"POSTGRES_PASSWORD" env-required:"true"`
+	// Время на завершение запросов при остановке сервиса
+	ShutdownTimeout time.Duration `env:"SERVER_SHUTDOWN_TIMEOUT" env-default:"10s"`
+	// postgres или memory
+	Storage         string `env:"STORAGE" env-default:"postgres"`
+	StorageSeedFile string `env:"STORAGE_SEED_FILE"`
+}
+
+type DbConfig struct {
+	Host     string `env:"POSTGRES_HOST" env-required:"true"`
+	Port     int    `env:"POSTGRES_PORT" env-required:"true"`
+	Dbname   string `env:"POSTGRES_DATABASE" env-required:"true"`
+	User     string `env:"POSTGRES_USERNAME" env-required:"true"`
+	Password string `env:"POSTGRES_PASSWORD" env-required:"true"`
+}
+
+type AuthConfig struct {
+	Secret string `env:"AUTH_SECRET" env-required:"true"`
+}
+
+// Повторы доставки событий подпискам: пауза между попытками удваивается от RetryInterval до MaxRetryInterval
+type WebhookConfig struct {
+	MaxAttempts      int           `env:"WEBHOOK_MAX_ATTEMPTS" env-default:"5"`
+	RetryInterval    time.Duration `env:"WEBHOOK_RETRY_INTERVAL" env-default:"1s"`
+	MaxRetryInterval time.Duration `env:"WEBHOOK_MAX_RETRY_INTERVAL" env-default:"1m"`
+	Timeout          time.Duration `env:"WEBHOOK_TIMEOUT" env-default:"10s"`
+	PollInterval     time.Duration `env:"WEBHOOK_POLL_INTERVAL" env-default:"1s"`
+	// Разрешить подписки на адреса loopback, link-local и частных сетей
+	AllowPrivateTargets bool `env:"WEBHOOK_ALLOW_PRIVATE_TARGETS" env-default:"false"`
+}
+
+// Передача событий из outbox. Получатели events (поток /api/events) и webhook работают всегда,
+// log и file - если включены
+type OutboxConfig struct {
+	PollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" env-default:"1s"`
+	LogSink      bool          `env:"OUTBOX_LOG_SINK" env-default:"false"`
+	// Файл, в который дописываются события, пустой - не писать
+	FileSink string `env:"OUTBOX_FILE_SINK"`
+	// Через сколько удалять события, обработанные всеми получателями, 0 - не удалять
+	Retention time.Duration `env:"OUTBOX_RETENTION" env-default:"168h"`
 }
 
 func main() {
 
-	dbConfig, err := ReadDbConfig()
+	serverConfig, err := ReadServerConfig()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	authConfig, err := ReadAuthConfig()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	webhookConfig, err := ReadWebhookConfig()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	outboxConfig, err := ReadOutboxConfig()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	var store *repository.Store
+	switch serverConfig.Storage {
+	case "postgres":
+		db, err := ConnectDb()
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		defer db.Close()
+		store = postgresRepository.NewStore(db)
+		metrics.RegisterDBStats(db.DB, "postgres")
+	case "memory":
+		var seed *memory.Seed
+		if serverConfig.StorageSeedFile != "" {
+			seed, err = memory.LoadSeed(serverConfig.StorageSeedFile)
+			if err != nil {
+				log.Error(err)
+				return
+			}
+		}
+		store = memory.NewStore(seed)
+		log.Info("In-memory storage is used.")
+	default:
+		log.Error(fmt.Errorf("Unknown storage: %s", serverConfig.Storage))
+		return
+	}
+
+	dispatcher := webhook.NewDispatcher(store, webhook.Config{
+		Retry: webhook.RetryPolicy{
+			MaxAttempts:     webhookConfig.MaxAttempts,
+			InitialInterval: webhookConfig.RetryInterval,
+			MaxInterval:     webhookConfig.MaxRetryInterval,
+		},
+		Timeout:             webhookConfig.Timeout,
+		PollInterval:        webhookConfig.PollInterval,
+		AllowPrivateTargets: webhookConfig.AllowPrivateTargets,
+	})
+	dispatcherCtx, stopDispatcher := context.WithCancel(context.Background())
+	dispatcherDone := make(chan struct{})
+	go func() {
+		dispatcher.Run(dispatcherCtx)
+		close(dispatcherDone)
+	}()
+	broker := events.NewBroker()
+	brokerSink, err := outbox.NewBrokerSink(store, broker)
 	if err != nil {
 		log.Error(err)
 		return
 	}
+	sinks := []outbox.Sink{brokerSink, dispatcher}
+	if outboxConfig.LogSink {
+		sinks = append(sinks, outbox.LogSink{})
+	}
+	if outboxConfig.FileSink != "" {
+		fileSink, err := outbox.NewFileSink(outboxConfig.FileSink)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		defer fileSink.Close()
+		sinks = append(sinks, fileSink)
+	}
+	relay := outbox.NewRelay(store, outboxConfig.PollInterval, sinks...)
+	relay.SetRetention(outboxConfig.Retention)
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	relayDone := make(chan struct{})
+	go func() {
+		relay.Run(relayCtx)
+		close(relayDone)
+	}()
+
+	routes := http.InitRoutes(store, validator.NewValidator(store), auth.NewAuth(store, authConfig.Secret), broker, dispatcher, relay)
+
+	// По SIGINT или SIGTERM сервер перестает принимать запросы и дожидается текущих, затем outbox передает
+	// оставшиеся события, а обработчик подписок завершает начатые доставки
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := &nethttp.Server{Addr: serverConfig.ServerAddress, Handler: routes}
+	// Потоки /api/events не завершаются сами, без закрытия брокера Shutdown ждал бы их до истечения времени
+	server.RegisterOnShutdown(broker.Close)
+	shutdownDone := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		log.Info("Shutting down.")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serverConfig.ShutdownTimeout)
+		defer cancel()
+		err := server.Shutdown(shutdownCtx)
+		if err != nil {
+			log.Error(err)
+		}
+		close(shutdownDone)
+	}()
+
+	err = server.ListenAndServe()
+	if err != nil && err != nethttp.ErrServerClosed {
+		log.Error(err)
+		stop()
+	}
+	<-shutdownDone
+
+	stopRelay()
+	<-relayDone
+	stopDispatcher()
+	<-dispatcherDone
+}
+
+func ConnectDb() (*sqlx.DB, error) {
+	dbConfig, err := ReadDbConfig()
+	if err != nil {
+		return nil, err
+	}
 
 	psqlInfo := fmt.Sprintf("host=%s port=%d user=%s "+
 		"password=%s dbname=%s sslmode=disable",
@@ -40,10 +217,8 @@ func main() {
 
 	db, err := sqlx.Connect("postgres", psqlInfo)
 	if err != nil {
-		log.Error(err)
-		return
+		return nil, err
 	}
-	defer db.Close()
 
 	err = db.Ping()
 	if err != nil {
@@ -53,8 +228,8 @@ func main() {
 
 	driver, err := postgres.WithInstance(db.DB, &postgres.Config{})
 	if err != nil {
-		log.Error(err)
-		return
+		db.Close()
+		return nil, err
 	}
 
 	m, err := migrate.NewWithDatabaseInstance(
@@ -71,11 +246,16 @@ func main() {
 	}
 	log.Info("Verification and application of missing migrations is completed.")
 
-	auth.InitAuth(db)
-	validator.InitValidator(db)
-	routes := http.InitRoutes(db)
+	return db, nil
+}
 
-	routes.Run(dbConfig.ServerAddress)
+func ReadServerConfig() (*ServerConfig, error) {
+	var serverConfig ServerConfig
+	err := cleanenv.ReadEnv(&serverConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Server config error: %w", err)
+	}
+	return &serverConfig, nil
 }
 
 func ReadDbConfig() (*DbConfig, error) {
@@ -86,3 +266,34 @@ func ReadDbConfig() (*DbConfig, error) {
 	}
 	return &dbconfig, nil
 }
+
+func ReadAuthConfig() (*AuthConfig, error) {
+	var authConfig AuthConfig
+	err := cleanenv.ReadEnv(&authConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Auth config error: %w", err)
+	}
+	err = auth.CheckSecret(authConfig.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("Auth config error: %w", err)
+	}
+	return &authConfig, nil
+}
+
+func ReadWebhookConfig() (*WebhookConfig, error) {
+	var webhookConfig WebhookConfig
+	err := cleanenv.ReadEnv(&webhookConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Webhook config error: %w", err)
+	}
+	return &webhookConfig, nil
+}
+
+func ReadOutboxConfig() (*OutboxConfig, error) {
+	var outboxConfig OutboxConfig
+	err := cleanenv.ReadEnv(&outboxConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Outbox config error: %w", err)
+	}
+	return &outboxConfig, nil
+}
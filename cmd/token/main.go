@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"avitoTask/internal/auth"
+
+	"github.com/ilyakaznacheev/cleanenv"
+	log "github.com/sirupsen/logrus"
+)
+
+type TokenConfig struct {
+	Secret string        `env:"AUTH_SECRET" env-required:"true"`
+	TTL    time.Duration `env:"AUTH_TOKEN_TTL" env-default:"24h"`
+}
+
+// Выпуск токена для сотрудника: AUTH_SECRET=... go run ./cmd/token -username <имя пользователя>
+func main() {
+	username := flag.String("username", "", "имя пользователя сотрудника")
+	flag.Parse()
+	if *username == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var tokenConfig TokenConfig
+	err := cleanenv.ReadEnv(&tokenConfig)
+	if err == nil {
+		err = auth.CheckSecret(tokenConfig.Secret)
+	}
+	if err != nil {
+		log.Error(fmt.Errorf("Auth config error: %w", err))
+		os.Exit(1)
+	}
+
+	token, err := auth.NewAuth(nil, tokenConfig.Secret).IssueToken(*username, tokenConfig.TTL)
+	if err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+	fmt.Println(token)
+}
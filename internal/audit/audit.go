@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"encoding/json"
+
+	"avitoTask/internal/repository"
+)
+
+// Виды сущностей журнала
+const (
+	EntityTender       = "tender"
+	EntityBid          = "bid"
+	EntityOrganization = "organization"
+	EntityWebhook      = "webhook"
+)
+
+var EntityTypesConst []string = []string{EntityTender, EntityBid, EntityOrganization, EntityWebhook}
+
+// Действия журнала
+const (
+	ActionCreate       = "create"
+	ActionEdit         = "edit"
+	ActionStatusChange = "status_change"
+	ActionRollback     = "rollback"
+	ActionDecision     = "decision"
+	ActionFeedback     = "feedback"
+	ActionGrantRole    = "grant_role"
+	ActionRevokeRole   = "revoke_role"
+	ActionDelete       = "delete"
+	ActionRedeliver    = "redeliver"
+	ActionSetQuorum    = "set_quorum"
+)
+
+// Пользователь и запрос, от имени которых выполняется изменение
+type Actor struct {
+	Username  string
+	RequestId string
+	Ip        string
+}
+
+// Изменение сущности. Before и After - снимки до и после изменения, nil - снимка нет
+type Change struct {
+	Action         string
+	EntityType     string
+	EntityId       string
+	OrganizationId string
+	Before         any
+	After          any
+}
+
+// Записывает изменение в журнал. Вызывается в транзакции изменения, чтобы запись появлялась
+// только вместе с ним
+func Record(tx *repository.Store, actor Actor, change Change) error {
+	before, err := snapshot(change.Before)
+	if err != nil {
+		return err
+	}
+	after, err := snapshot(change.After)
+	if err != nil {
+		return err
+	}
+	return tx.Audit.Create(&repository.AuditEntry{
+		Actor:          actor.Username,
+		Action:         change.Action,
+		EntityType:     change.EntityType,
+		EntityId:       change.EntityId,
+		OrganizationId: change.OrganizationId,
+		Before:         before,
+		After:          after,
+		RequestId:      actor.RequestId,
+		Ip:             actor.Ip,
+	})
+}
+
+func snapshot(entity any) (string, error) {
+	if entity == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
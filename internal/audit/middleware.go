@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"avitoTask/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	RequestIdHeader = "X-Request-Id"
+	RequestIdKey    = "requestId"
+	// Длина идентификатора запроса, переданного клиентом, ограничена размером столбца audit_log.request_id
+	maxRequestIdLength = 100
+)
+
+// Берет идентификатор запроса из заголовка X-Request-Id или создает новый и возвращает его в ответе
+func RequestId() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(RequestIdHeader)
+		if requestId == "" || len(requestId) > maxRequestIdLength {
+			requestId = uuid.NewString()
+		}
+		c.Set(RequestIdKey, requestId)
+		c.Header(RequestIdHeader, requestId)
+		c.Next()
+	}
+}
+
+func GetActor(c *gin.Context) Actor {
+	return Actor{
+		Username:  auth.GetUsername(c),
+		RequestId: c.GetString(RequestIdKey),
+		Ip:        c.ClientIP(),
+	}
+}
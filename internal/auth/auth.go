@@ -1,86 +1,83 @@
 package auth
 
 import (
-	"github.com/jmoiron/sqlx"
+	"database/sql"
+
+	"avitoTask/internal/repository"
+
 	log "github.com/sirupsen/logrus"
 )
 
-var db *sqlx.DB
-
-func InitAuth(conn *sqlx.DB) {
-	db = conn
+type Auth struct {
+	store  *repository.Store
+	secret []byte
 }
 
-func CheckUserCanManageTender(username, organizationId string) error {
-	var isResponsibleOrganization bool
-	log.Info("organizationId = " + organizationId)
-	log.Info("username = " + username)
-	query := `SELECT true
-				FROM organization_responsible org_r
-					JOIN employee emp ON emp.id = org_r.user_id
-				WHERE org_r.organization_id = $1 AND emp.username = $2`
-	return db.Get(&isResponsibleOrganization, query, organizationId, username)
+func NewAuth(store *repository.Store, authSecret string) *Auth {
+	return &Auth{store: store, secret: []byte(authSecret)}
 }
-func CheckUserViewTender(username, tenderId string) error {
-	var canView bool
+
+func (a *Auth) CheckUserViewTender(username, tenderId string) error {
 	log.Info("tenderId = " + tenderId)
 	log.Info("username = " + username)
-	query := `SELECT true
-				FROM   tender t
-				WHERE  id = $1
-					AND ( t.status = 'Published'
-							OR EXISTS(SELECT 1
-										FROM   organization_responsible org_r
-											join employee emp
-												ON emp.id = org_r.user_id
-													AND emp.username = $2
-										WHERE  org_r.organization_id = t.organization_id)
-								AND t.status IN ( 'Created', 'Closed' ) ) `
-	return db.Get(&canView, query, tenderId, username)
+	tender, err := a.store.Tenders.Get(tenderId)
+	if err != nil {
+		return err
+	}
+	return a.CheckUserViewTenderState(username, tender)
+}
+
+// Проверяет видимость тендера в переданном состоянии, например в состоянии из события
+func (a *Auth) CheckUserViewTenderState(username string, tender *repository.Tender) error {
+	if tender.Status == "Published" {
+		return nil
+	}
+	return a.Authorize(username, tender.OrganizationId, ActionViewTender)
 }
 
-func CheckUserCanManageBid(username, autorType, authorId string) error {
-	var canManage bool
+func (a *Auth) CheckUserCanManageBid(username, autorType, authorId string) error {
 	log.Info("autorType = " + autorType)
 	log.Info("authorId = " + authorId)
 	log.Info("username = " + username)
-	query := `SELECT TRUE
-				FROM employee emp
-				WHERE emp.username = $1
-				AND ('User' = $3 AND emp.id = $2
-					OR 'Organization' = $3 AND EXISTS(SELECT 1
-														FROM organization_responsible org_r
-														WHERE org_r.organization_id = $2 AND org_r.user_id = emp.id))`
-	return db.Get(&canManage, query, username, authorId, autorType)
+	if autorType == "Organization" {
+		return a.Authorize(username, authorId, ActionManageBid)
+	}
+	return a.checkUserIsEmployee(username, authorId)
 }
-func CheckUserViewBid(username, bidId string) error {
-	var canView bool
+
+// Предложение видно стороне автора в любом статусе, а опубликованное - еще и сотрудникам организации тендера
+// с правом просмотра предложений. Правило совпадает с фильтром списка предложений тендера
+func (a *Auth) CheckUserViewBid(username, bidId string) error {
 	log.Info("bidId = " + bidId)
 	log.Info("username = " + username)
-	query := `SELECT true
-				FROM bid b
-				WHERE b.id = $1
-				AND (b.status IN ('Created', 'Canceled') 
-						AND (author_type = 'User' AND EXISTS(SELECT 1
-															FROM employee emp
-															WHERE emp.id = b.author_id AND emp.username = $2)
-								OR b.author_type = 'Organization'
-									AND EXISTS(SELECT 1
-												FROM organization_responsible org_r
-													JOIN employee emp ON emp.id = org_r.user_id AND emp.username = $2
-												WHERE org_r.organization_id = b.author_id))
-					OR
-					b.status = 'Published') `
-	return db.Get(&canView, query, bidId, username)
+	bid, err := a.store.Bids.Get(bidId)
+	if err != nil {
+		return err
+	}
+	return a.CheckUserViewBidState(username, bid)
 }
-func CheckUserCanApproveBid(username, tenderId string) error {
-	var canManage bool
-	log.Info("tenderId = " + tenderId)
-	log.Info("username = " + username)
-	query := `SELECT TRUE
-				FROM tender t
-					JOIN organization_responsible org_r ON org_r.organization_id = t.organization_id
-					JOIN employee emp ON emp.id = org_r.user_id AND emp.username = $2
-				WHERE t.id = $1`
-	return db.Get(&canManage, query, tenderId, username)
+
+// Проверяет видимость предложения в переданном состоянии, например в состоянии из события
+func (a *Auth) CheckUserViewBidState(username string, bid *repository.Bid) error {
+	var err error
+	if bid.AuthorType == "Organization" {
+		err = a.Authorize(username, bid.AuthorId, ActionViewBid)
+	} else {
+		err = a.checkUserIsEmployee(username, bid.AuthorId)
+	}
+	if err != sql.ErrNoRows || bid.Status != "Published" {
+		return err
+	}
+	return a.AuthorizeTender(username, bid.TenderId, ActionViewBid)
+}
+
+func (a *Auth) checkUserIsEmployee(username, employeeId string) error {
+	userId, err := a.store.Employees.GetId(username)
+	if err != nil {
+		return err
+	}
+	if userId != employeeId {
+		return sql.ErrNoRows
+	}
+	return nil
 }
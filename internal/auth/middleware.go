@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"database/sql"
+	"strings"
+
+	"avitoTask/internal/error"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const UsernameKey = "username"
+
+// Определяет пользователя по токену из заголовка Authorization и кладет его в контекст запроса
+func (a *Auth) RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log.Info("Аутентификация")
+		header := c.GetHeader("Authorization")
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || token == "" {
+			error.GetTokenNotPassedError(c)
+			return
+		}
+
+		claims, err := a.ParseToken(token)
+		if err != nil {
+			error.GetInvalidTokenError(c, err)
+			return
+		}
+
+		err = a.store.Employees.Exists(claims.Subject)
+		if err == sql.ErrNoRows {
+			error.GetUserNotExistsOrIncorrectError(c)
+			return
+		} else if err != nil {
+			error.GetInternalServerError(c, err)
+			return
+		}
+
+		c.Set(UsernameKey, claims.Subject)
+		c.Next()
+	}
+}
+
+func GetUsername(c *gin.Context) string {
+	return c.GetString(UsernameKey)
+}
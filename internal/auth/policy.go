@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"database/sql"
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type Action string
+
+const (
+	ActionViewTender     Action = "tender:view"
+	ActionManageTender   Action = "tender:manage"
+	ActionViewBid        Action = "bid:view"
+	ActionManageBid      Action = "bid:manage"
+	ActionApproveBid     Action = "bid:approve"
+	ActionManageRoles    Action = "role:manage"
+	ActionManageWebhooks Action = "webhook:manage"
+	ActionViewAudit      Action = "audit:view"
+	ActionManageQuorum   Action = "quorum:manage"
+)
+
+var RolesConst []string = []string{"owner", "tender_manager", "approver", "viewer"}
+
+var RolePermissions map[string][]Action = map[string][]Action{
+	"owner":          {ActionViewTender, ActionManageTender, ActionViewBid, ActionManageBid, ActionApproveBid, ActionManageRoles, ActionManageWebhooks, ActionViewAudit, ActionManageQuorum},
+	"tender_manager": {ActionViewTender, ActionManageTender, ActionViewBid, ActionManageBid},
+	"approver":       {ActionViewTender, ActionViewBid, ActionApproveBid},
+	"viewer":         {ActionViewTender, ActionViewBid},
+}
+
+func RolesWithPermission(action Action) []string {
+	var roles []string
+	for _, role := range RolesConst {
+		if slices.Contains(RolePermissions[role], action) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// Проверяет, что у пользователя есть роль в организации, разрешающая действие.
+// Как и остальные проверки пакета, при отсутствии прав возвращает sql.ErrNoRows.
+func (a *Auth) Authorize(username, organizationId string, action Action) error {
+	log.Info("organizationId = " + organizationId)
+	log.Info("username = " + username)
+	log.Info("action = " + string(action))
+	roles, err := a.store.Organizations.GetMemberRoles(organizationId, username)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if slices.Contains(RolePermissions[role], action) {
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (a *Auth) AuthorizeTender(username, tenderId string, action Action) error {
+	tender, err := a.store.Tenders.Get(tenderId)
+	if err != nil {
+		return err
+	}
+	return a.Authorize(username, tender.OrganizationId, action)
+}
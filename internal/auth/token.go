@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+type Claims struct {
+	Subject   string `json:"sub"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Минимальная длина секрета подписи токенов в байтах: не меньше размера ключа HMAC-SHA256
+const MinSecretLength = 32
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+	ErrWeakSecret   = errors.New("AUTH_SECRET must be at least 32 bytes")
+)
+
+// Проверяет секрет подписи токенов при запуске: пустой или короткий секрет позволил бы подделать токен
+func CheckSecret(secret string) error {
+	if len(secret) < MinSecretLength {
+		return ErrWeakSecret
+	}
+	return nil
+}
+
+// Заголовок JWT одинаковый для всех токенов: подпись HMAC-SHA256
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+func (a *Auth) IssueToken(username string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := Claims{Subject: username, IssuedAt: now.Unix(), ExpiresAt: now.Add(ttl).Unix()}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	unsigned := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + a.sign(unsigned), nil
+}
+
+func (a *Auth) ParseToken(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != tokenHeader {
+		return nil, ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(a.sign(parts[0]+"."+parts[1])), []byte(parts[2])) {
+		return nil, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+	return &claims, nil
+}
+
+func (a *Auth) sign(unsigned string) string {
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"avitoTask/internal/repository/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func TestCheckSecret(t *testing.T) {
+	for _, secret := range []string{"", "secret", testSecret[:MinSecretLength-1]} {
+		if err := CheckSecret(secret); err != ErrWeakSecret {
+			t.Errorf("секрет длиной %d принят", len(secret))
+		}
+	}
+	if err := CheckSecret(testSecret); err != nil {
+		t.Errorf("секрет длиной %d отклонен: %v", len(testSecret), err)
+	}
+}
+
+func TestParseToken(t *testing.T) {
+	authorization := NewAuth(nil, testSecret)
+	token, err := authorization.IssueToken("alice", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := authorization.ParseToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "alice" {
+		t.Fatalf("пользователь токена %s, ожидался alice", claims.Subject)
+	}
+
+	expired, err := authorization.IssueToken("alice", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authorization.ParseToken(expired); err != ErrTokenExpired {
+		t.Errorf("просроченный токен, ошибка %v", err)
+	}
+
+	foreign, err := NewAuth(nil, strings.Repeat("x", MinSecretLength)).IssueToken("alice", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authorization.ParseToken(foreign); err != ErrInvalidToken {
+		t.Errorf("токен с чужой подписью, ошибка %v", err)
+	}
+
+	// Подмена пользователя в полезной нагрузке без новой подписи
+	other, err := authorization.IssueToken("mallory", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts, otherParts := strings.Split(token, "."), strings.Split(other, ".")
+	tampered := parts[0] + "." + otherParts[1] + "." + parts[2]
+	if _, err := authorization.ParseToken(tampered); err != ErrInvalidToken {
+		t.Errorf("токен с измененной нагрузкой, ошибка %v", err)
+	}
+}
+
+func TestRequireUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.NewStore(&memory.Seed{
+		Employees: []memory.Employee{{Id: "11111111-1111-1111-1111-111111111111", Username: "alice"}},
+	})
+	authorization := NewAuth(store, testSecret)
+	routes := gin.New()
+	routes.GET("/", authorization.RequireUser(), func(c *gin.Context) {
+		c.String(http.StatusOK, GetUsername(c))
+	})
+
+	valid, err := authorization.IssueToken("alice", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown, err := authorization.IssueToken("nobody", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		header string
+		code   int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer ", http.StatusUnauthorized},
+		{valid, http.StatusUnauthorized},
+		{"Basic " + valid, http.StatusUnauthorized},
+		{"Bearer invalid", http.StatusUnauthorized},
+		{"Bearer " + unknown, http.StatusUnauthorized},
+		{"Bearer " + valid, http.StatusOK},
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		if test.header != "" {
+			request.Header.Set("Authorization", test.header)
+		}
+		recorder := httptest.NewRecorder()
+		routes.ServeHTTP(recorder, request)
+		if recorder.Code != test.code {
+			t.Errorf("заголовок %q: статус %d, ожидался %d", test.header, recorder.Code, test.code)
+		}
+		if test.code == http.StatusOK && recorder.Body.String() != "alice" {
+			t.Errorf("пользователь запроса %q, ожидался alice", recorder.Body.String())
+		}
+	}
+}
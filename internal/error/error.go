@@ -12,15 +12,17 @@ type InternalErrorBody struct {
 }
 
 var (
-	UserNotPassedError                          = InternalErrorBody{"Пользователь должен быть указан."}
+	TokenNotPassedError                         = InternalErrorBody{"Необходимо передать токен в заголовке Authorization: Bearer <токен>."}
 	UserNotExistsOrIncorrectError               = InternalErrorBody{"Пользователь не существует или некорректен."}
 	OrganizationNotExistsOrIncorrectError       = InternalErrorBody{"Организация не существует или некорректна."}
 	NewStatusNotPassedError                     = InternalErrorBody{"Новый статус должен быть указан."}
 	TenderIdNotPassedError                      = InternalErrorBody{"Идентификатор тендера должен быть указан."}
 	TenderNotFoundError                         = InternalErrorBody{"Указанный тендер не существует."}
 	BidNotFoundError                            = InternalErrorBody{"Указанное предложение не существует."}
+	AuthorBidNotFoundError                      = InternalErrorBody{"У указанного автора нет предложений по тендеру."}
 	BidIdNotPassedError                         = InternalErrorBody{"Идентификатор предложения должен быть указан."}
 	AuthorNotFoundError                         = InternalErrorBody{"Указанный автор не существует."}
+	AuthorNotPassedError                        = InternalErrorBody{"Автор должен быть указан."}
 	UserNotResponsibleOrganizationError         = InternalErrorBody{"Необходимо быть ответственным за организацию."}
 	UserNotAuthorOrResponsibleOrganizationError = InternalErrorBody{"Необходимо быть автором или ответственным за организацию."}
 	InvalidVersionError                         = InternalErrorBody{"Указанная версия больше или равна текущей версии тендера."}
@@ -33,6 +35,35 @@ var (
 	DecisionNotPassedError                      = InternalErrorBody{"Решение должено быть указано."}
 	BidAlreadyHasDecisionError                  = InternalErrorBody{"Решение по предложению уже принято."}
 	UserHasDecisionForBidError                  = InternalErrorBody{"Вы уже приняли решение по предложению."}
+	InvalidTenderStatusTransitionError          = InternalErrorBody{"Недопустимый переход статуса тендера."}
+	InvalidBidStatusTransitionError             = InternalErrorBody{"Недопустимый переход статуса предложения."}
+	TenderNotPublishedError                     = InternalErrorBody{"Предложения можно создавать и публиковать только для опубликованных тендеров."}
+	BidReadOnlyError                            = InternalErrorBody{"Предложение недоступно для изменения: по нему принято решение или тендер закрыт."}
+	VersionBrokenError                          = InternalErrorBody{"Снимок версии поврежден, откат к ней невозможен."}
+	RoleNotPassedError                          = InternalErrorBody{"Роль должна быть указана."}
+	InvalidRoleError                            = InternalErrorBody{"Недопустимая роль"}
+	RoleNotFoundError                           = InternalErrorBody{"У сотрудника нет указанной роли в организации."}
+	EmployeeNotPassedError                      = InternalErrorBody{"Сотрудник должен быть указан."}
+	EmployeeNotFoundError                       = InternalErrorBody{"Указанный сотрудник не существует."}
+	UserCannotManageRolesError                  = InternalErrorBody{"Недостаточно прав для управления ролями организации."}
+	QuorumNotPassedError                        = InternalErrorBody{"Кворум должен быть указан."}
+	InvalidQuorumError                          = InternalErrorBody{"Кворум должен быть целым числом не меньше 1."}
+	UserCannotManageQuorumError                 = InternalErrorBody{"Недостаточно прав для управления кворумом организации."}
+	FeedbackNotPassedError                      = InternalErrorBody{"Отзыв должен быть указан."}
+	InvalidFeedbackError                        = InternalErrorBody{"Отзыв не должен превышать 1000 символов."}
+	VersionNotPassedError                       = InternalErrorBody{"Версия для сравнения должна быть указана."}
+	InvalidCommentError                         = InternalErrorBody{"Комментарий не должен превышать 500 символов."}
+	SearchQueryNotPassedError                   = InternalErrorBody{"Строка поиска должна быть указана."}
+	InvalidSearchTypeError                      = InternalErrorBody{"Недопустимый вид записей для поиска"}
+	InvalidWebhookUrlError                      = InternalErrorBody{"Адрес подписки должен быть абсолютным http или https URL."}
+	InvalidEventTypeError                       = InternalErrorBody{"Недопустимый вид события"}
+	WebhookTargetNotAllowedError                = InternalErrorBody{"Адрес подписки не должен указывать на локальную или частную сеть."}
+	UserCannotManageWebhooksError               = InternalErrorBody{"Недостаточно прав для управления подписками организации."}
+	WebhookNotFoundError                        = InternalErrorBody{"Указанная подписка не существует."}
+	WebhookDeliveryNotFoundError                = InternalErrorBody{"Указанная доставка не существует."}
+	OrganizationIdNotPassedError                = InternalErrorBody{"Идентификатор организации должен быть указан."}
+	InvalidEntityTypeError                      = InternalErrorBody{"Недопустимый вид сущности"}
+	UserCannotViewAuditError                    = InternalErrorBody{"Недостаточно прав для просмотра журнала аудита организации."}
 )
 
 // 400 (StatusBadRequest) - Данные неправильно сформированы или не соответствуют требованиям.
@@ -60,6 +91,11 @@ func GetAuthorNotFoundError(c *gin.Context) {
 	c.AbortWithStatusJSON(http.StatusBadRequest, AuthorNotFoundError)
 }
 
+func GetAuthorNotPassedError(c *gin.Context) {
+	log.Error(AuthorNotPassedError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, AuthorNotPassedError)
+}
+
 func GetOrganizationNotExistsOrIncorrectError(c *gin.Context) {
 	log.Error(OrganizationNotExistsOrIncorrectError)
 	c.AbortWithStatusJSON(http.StatusBadRequest, OrganizationNotExistsOrIncorrectError)
@@ -98,11 +134,89 @@ func GetUserHasDecisionForBidError(c *gin.Context) {
 	c.AbortWithStatusJSON(http.StatusBadRequest, UserHasDecisionForBidError)
 }
 
+func GetFeedbackNotPassedError(c *gin.Context) {
+	log.Error(FeedbackNotPassedError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, FeedbackNotPassedError)
+}
+func GetInvalidFeedbackError(c *gin.Context) {
+	log.Error(InvalidFeedbackError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, InvalidFeedbackError)
+}
+
+func GetInvalidCommentError(c *gin.Context) {
+	log.Error(InvalidCommentError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, InvalidCommentError)
+}
+
+func GetRoleNotPassedError(c *gin.Context) {
+	log.Error(RoleNotPassedError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, RoleNotPassedError)
+}
+func GetQuorumNotPassedError(c *gin.Context) {
+	log.Error(QuorumNotPassedError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, QuorumNotPassedError)
+}
+func GetInvalidQuorumError(c *gin.Context) {
+	log.Error(InvalidQuorumError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, InvalidQuorumError)
+}
+func GetInvalidRoleError(c *gin.Context) {
+	log.Error(InvalidRoleError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, InvalidRoleError)
+}
+func GetEmployeeNotPassedError(c *gin.Context) {
+	log.Error(EmployeeNotPassedError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, EmployeeNotPassedError)
+}
+func GetEmployeeNotFoundError(c *gin.Context) {
+	log.Error(EmployeeNotFoundError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, EmployeeNotFoundError)
+}
+func GetVersionNotPassedError(c *gin.Context) {
+	log.Error(VersionNotPassedError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, VersionNotPassedError)
+}
+
+func GetSearchQueryNotPassedError(c *gin.Context) {
+	log.Error(SearchQueryNotPassedError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, SearchQueryNotPassedError)
+}
+func GetInvalidSearchTypeError(c *gin.Context) {
+	log.Error(InvalidSearchTypeError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, InvalidSearchTypeError)
+}
+
+func GetInvalidWebhookUrlError(c *gin.Context) {
+	log.Error(InvalidWebhookUrlError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, InvalidWebhookUrlError)
+}
+func GetInvalidEventTypeError(c *gin.Context) {
+	log.Error(InvalidEventTypeError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, InvalidEventTypeError)
+}
+func GetWebhookTargetNotAllowedError(c *gin.Context) {
+	log.Error(WebhookTargetNotAllowedError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, WebhookTargetNotAllowedError)
+}
+
+func GetOrganizationIdNotPassedError(c *gin.Context) {
+	log.Error(OrganizationIdNotPassedError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, OrganizationIdNotPassedError)
+}
+func GetInvalidEntityTypeError(c *gin.Context) {
+	log.Error(InvalidEntityTypeError)
+	c.AbortWithStatusJSON(http.StatusBadRequest, InvalidEntityTypeError)
+}
+
 // 401 (StatusUnauthorized) - Пользователь не существует или некорректен.
 
-func GetUserNotPassedError(c *gin.Context) {
-	log.Error(UserNotPassedError)
-	c.AbortWithStatusJSON(http.StatusUnauthorized, UserNotPassedError)
+func GetTokenNotPassedError(c *gin.Context) {
+	log.Error(TokenNotPassedError)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, TokenNotPassedError)
+}
+func GetInvalidTokenError(c *gin.Context, err error) {
+	log.Error(err)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, InternalErrorBody{"Токен недействителен: " + err.Error()})
 }
 
 func GetUserNotExistsOrIncorrectError(c *gin.Context) {
@@ -121,6 +235,23 @@ func GetUserNotAuthorOrResponsibleOrganizationError(c *gin.Context) {
 	c.AbortWithStatusJSON(http.StatusForbidden, UserNotAuthorOrResponsibleOrganizationError)
 }
 
+func GetUserCannotManageRolesError(c *gin.Context) {
+	log.Error(UserCannotManageRolesError)
+	c.AbortWithStatusJSON(http.StatusForbidden, UserCannotManageRolesError)
+}
+func GetUserCannotManageQuorumError(c *gin.Context) {
+	log.Error(UserCannotManageQuorumError)
+	c.AbortWithStatusJSON(http.StatusForbidden, UserCannotManageQuorumError)
+}
+func GetUserCannotManageWebhooksError(c *gin.Context) {
+	log.Error(UserCannotManageWebhooksError)
+	c.AbortWithStatusJSON(http.StatusForbidden, UserCannotManageWebhooksError)
+}
+func GetUserCannotViewAuditError(c *gin.Context) {
+	log.Error(UserCannotViewAuditError)
+	c.AbortWithStatusJSON(http.StatusForbidden, UserCannotViewAuditError)
+}
+
 func GetUserNotViewTenderError(c *gin.Context) {
 	log.Error(UserNotViewTenderError)
 	c.AbortWithStatusJSON(http.StatusForbidden, UserNotViewTenderError)
@@ -144,6 +275,49 @@ func GetBidNotFoundError(c *gin.Context) {
 	log.Error(BidNotFoundError)
 	c.AbortWithStatusJSON(http.StatusNotFound, BidNotFoundError)
 }
+func GetAuthorBidNotFoundError(c *gin.Context) {
+	log.Error(AuthorBidNotFoundError)
+	c.AbortWithStatusJSON(http.StatusNotFound, AuthorBidNotFoundError)
+}
+func GetRoleNotFoundError(c *gin.Context) {
+	log.Error(RoleNotFoundError)
+	c.AbortWithStatusJSON(http.StatusNotFound, RoleNotFoundError)
+}
+func GetWebhookNotFoundError(c *gin.Context) {
+	log.Error(WebhookNotFoundError)
+	c.AbortWithStatusJSON(http.StatusNotFound, WebhookNotFoundError)
+}
+func GetWebhookDeliveryNotFoundError(c *gin.Context) {
+	log.Error(WebhookDeliveryNotFoundError)
+	c.AbortWithStatusJSON(http.StatusNotFound, WebhookDeliveryNotFoundError)
+}
+
+// 409 (StatusConflict) - Действие противоречит текущему состоянию тендера или предложения.
+
+func GetInvalidTenderStatusTransitionError(c *gin.Context) {
+	log.Error(InvalidTenderStatusTransitionError)
+	c.AbortWithStatusJSON(http.StatusConflict, InvalidTenderStatusTransitionError)
+}
+func GetInvalidBidStatusTransitionError(c *gin.Context) {
+	log.Error(InvalidBidStatusTransitionError)
+	c.AbortWithStatusJSON(http.StatusConflict, InvalidBidStatusTransitionError)
+}
+func GetTenderNotPublishedError(c *gin.Context) {
+	log.Error(TenderNotPublishedError)
+	c.AbortWithStatusJSON(http.StatusConflict, TenderNotPublishedError)
+}
+func GetBidReadOnlyError(c *gin.Context) {
+	log.Error(BidReadOnlyError)
+	c.AbortWithStatusJSON(http.StatusConflict, BidReadOnlyError)
+}
+func GetVersionBrokenError(c *gin.Context) {
+	log.Error(VersionBrokenError)
+	c.AbortWithStatusJSON(http.StatusConflict, VersionBrokenError)
+}
+func GetStateConflictError(c *gin.Context, err error) {
+	log.Error(err)
+	c.AbortWithStatusJSON(http.StatusConflict, InternalErrorBody{err.Error()})
+}
 
 // 500 (StatusInternalServerError) - Сервер не готов обрабатывать запросы, если ответ статусом 500 или любой другой, кроме 200.
 
@@ -0,0 +1,93 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Виды событий
+const (
+	TenderStatusChanged = "tender_status_changed"
+	TenderClosed        = "tender_closed"
+	BidPublished        = "bid_published"
+	BidDecision         = "bid_decision"
+)
+
+var TypesConst []string = []string{TenderStatusChanged, TenderClosed, BidPublished, BidDecision}
+
+// Событие изменения тендера или предложения. Для событий предложения заполняются BidId, автор
+// и статус предложения, для событий тендера - организация, новый и предыдущий статус тендера.
+// По этому состоянию решается, кому доставить событие, а не по текущему состоянию записи
+type Event struct {
+	Type           string `json:"type"`
+	TenderId       string `json:"tenderId"`
+	OrganizationId string `json:"organizationId,omitempty"`
+	BidId          string `json:"bidId,omitempty"`
+	AuthorType     string `json:"authorType,omitempty"`
+	AuthorId       string `json:"authorId,omitempty"`
+	Status         string `json:"status,omitempty"`
+	PreviousStatus string `json:"previousStatus,omitempty"`
+	Decision       string `json:"decision,omitempty"`
+	OccurredAt     string `json:"occurredAt"`
+}
+
+func NewEvent(eventType, tenderId string) Event {
+	return Event{Type: eventType, TenderId: tenderId, OccurredAt: time.Now().UTC().Format(time.RFC3339Nano)}
+}
+
+type Publisher interface {
+	Publish(event Event)
+}
+
+// Размер очереди подписчика. Если подписчик не успевает читать, новые события для него отбрасываются
+const subscriberBuffer = 64
+
+// Рассылает события подписчикам внутри процесса
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+	closed      bool
+}
+
+func NewBroker() *Broker {
+	return &Broker{subscribers: map[chan Event]struct{}{}}
+}
+
+func (b *Broker) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// Подписывает на события, возвращает канал событий и функцию отписки
+func (b *Broker) Subscribe() (<-chan Event, func()) {
+	subscriber := make(chan Event, subscriberBuffer)
+	b.mu.Lock()
+	if b.closed {
+		close(subscriber)
+	} else {
+		b.subscribers[subscriber] = struct{}{}
+	}
+	b.mu.Unlock()
+	return subscriber, func() {
+		b.mu.Lock()
+		delete(b.subscribers, subscriber)
+		b.mu.Unlock()
+	}
+}
+
+// Закрывает каналы подписчиков, после этого новые подписки получают закрытый канал
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for subscriber := range b.subscribers {
+		close(subscriber)
+		delete(b.subscribers, subscriber)
+	}
+	b.closed = true
+}
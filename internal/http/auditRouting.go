@@ -0,0 +1,119 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"avitoTask/internal/audit"
+	"avitoTask/internal/auth"
+	apiError "avitoTask/internal/error"
+	"avitoTask/internal/repository"
+	"avitoTask/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// Запись журнала аудита, before и after отсутствуют, если снимка нет
+type auditEntry struct {
+	Id             int64           `json:"id"`
+	Actor          string          `json:"actor"`
+	Action         string          `json:"action"`
+	EntityType     string          `json:"entityType"`
+	EntityId       string          `json:"entityId"`
+	OrganizationId string          `json:"organizationId"`
+	Before         json.RawMessage `json:"before,omitempty"`
+	After          json.RawMessage `json:"after,omitempty"`
+	RequestId      string          `json:"requestId"`
+	Ip             string          `json:"ip"`
+	CreatedAt      string          `json:"createdAt"`
+}
+
+type AuditHandler struct {
+	auditLog *service.AuditService
+}
+
+func NewAuditHandler(auditLog *service.AuditService) *AuditHandler {
+	return &AuditHandler{auditLog: auditLog}
+}
+
+func (h *AuditHandler) InitAuditRoutes(routes *gin.RouterGroup) {
+	//GET
+	routes.GET("/audit", h.getAuditEntries)
+}
+
+func newAuditEntry(e *repository.AuditEntry) *auditEntry {
+	entry := &auditEntry{
+		Id:             e.Id,
+		Actor:          e.Actor,
+		Action:         e.Action,
+		EntityType:     e.EntityType,
+		EntityId:       e.EntityId,
+		OrganizationId: e.OrganizationId,
+		RequestId:      e.RequestId,
+		Ip:             e.Ip,
+		CreatedAt:      e.CreatedAt,
+	}
+	if e.Before != "" {
+		entry.Before = json.RawMessage(e.Before)
+	}
+	if e.After != "" {
+		entry.After = json.RawMessage(e.After)
+	}
+	return entry
+}
+
+func (h *AuditHandler) getAuditEntries(c *gin.Context) {
+	log.Info("Чтение параметров")
+	username := auth.GetUsername(c)
+	filter := repository.AuditFilter{
+		OrganizationId: c.Query("organizationId"),
+		EntityType:     c.Query("entityType"),
+		EntityId:       c.Query("entityId"),
+		Actor:          c.Query("actor"),
+	}
+	limit, offset, err := getPagination(c)
+	if err != nil {
+		apiError.GetInvalidRequestFormatOrParametersError(c, err)
+		return
+	}
+	filter.From, err = getTimeQuery(c, "from")
+	if err != nil {
+		apiError.GetInvalidRequestFormatOrParametersError(c, err)
+		return
+	}
+	filter.To, err = getTimeQuery(c, "to")
+	if err != nil {
+		apiError.GetInvalidRequestFormatOrParametersError(c, err)
+		return
+	}
+
+	log.Info("Валидация")
+	if filter.OrganizationId == "" {
+		apiError.GetOrganizationIdNotPassedError(c)
+		return
+	}
+	if err := uuid.Validate(filter.OrganizationId); err != nil {
+		apiError.GetInvalidRequestFormatOrParametersError(c, err)
+		return
+	}
+	if filter.EntityType != "" && !slices.Contains(audit.EntityTypesConst, filter.EntityType) {
+		apiError.GetInvalidEntityTypeError(c)
+		return
+	}
+
+	log.Info("Чтение")
+	entries, err := h.auditLog.List(username, filter, limit, offset)
+	if err != nil {
+		getServiceError(c, err)
+		return
+	}
+
+	auditEntries := make([]auditEntry, 0, len(entries))
+	for i := range entries {
+		auditEntries = append(auditEntries, *newAuditEntry(&entries[i]))
+	}
+	c.JSON(http.StatusOK, auditEntries)
+}
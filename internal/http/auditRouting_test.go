@@ -0,0 +1,32 @@
+package http
+
+import (
+	nethttp "net/http"
+	"strings"
+	"testing"
+)
+
+func TestAuditHidesDraftBidOfOtherAuthor(t *testing.T) {
+	s := newTestServer(t)
+	tenderId := s.publishedTender()
+	bidId := s.draftBid("bob", testBobId, tenderId)
+
+	path := "/audit?organizationId=" + testOrganizationId + "&entityType=bid&entityId=" + bidId
+	var entries []auditEntry
+	s.mustDo("alice", nethttp.MethodGet, path, nil, &entries)
+	if len(entries) != 1 {
+		t.Fatalf("записей %d, ожидалась 1", len(entries))
+	}
+	if entries[0].Before != nil || entries[0].After != nil {
+		t.Fatalf("владелец тендера видит черновик чужого предложения: %s", entries[0].After)
+	}
+
+	s.mustDo("bob", nethttp.MethodPut, "/bids/"+bidId+"/status?status=Published", nil, nil)
+	s.mustDo("alice", nethttp.MethodGet, path, nil, &entries)
+	if len(entries) != 2 {
+		t.Fatalf("записей %d, ожидалось 2", len(entries))
+	}
+	if !strings.Contains(string(entries[0].After), "Published") {
+		t.Fatalf("снимок опубликованного предложения скрыт: %s", entries[0].After)
+	}
+}
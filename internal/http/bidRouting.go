@@ -1,77 +1,206 @@
 package http
 
 import (
-	"database/sql"
-	"encoding/json"
 	"net/http"
 	"slices"
 	"strconv"
-	"time"
+	"unicode/utf8"
 
-	validator "avitoTask/internal"
+	"avitoTask/internal/audit"
 	"avitoTask/internal/auth"
 	"avitoTask/internal/error"
+	"avitoTask/internal/repository"
+	"avitoTask/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 )
 
+// Предложение в запросе на создание: status, version, createdAt и decision задает сервер, переданные значения игнорируются
 type bid struct {
-	Id              string  `json:"id" db:"id" binding:"max=100"`
-	Name            string  `json:"name" db:"name" binding:"required,max=100"`
-	Description     string  `json:"description" db:"description" binding:"required,max=500"`
-	Status          string  `json:"status" db:"status" binding:"required,oneof=Created Published Canceled"`
-	TenderId        string  `json:"tenderId" db:"tender_id" binding:"required,max=100"`
-	AuthorType      string  `json:"authorType" db:"author_type" binding:"required,max=100,oneof=Organization User"`
-	AuthorId        string  `json:"authorId" db:"author_id" binding:"required,max=100"`
-	Version         int     `json:"version" db:"version" binding:"required,min=1"`
-	CreatedAt       string  `json:"createdAt" db:"created_at" binding:"required"`
-	Decision        *string `json:"decision" db:"decision"`
+	Id              string  `json:"id" binding:"max=100"`
+	Name            string  `json:"name" binding:"required,max=100"`
+	Description     string  `json:"description" binding:"required,max=500"`
+	Status          string  `json:"status"`
+	TenderId        string  `json:"tenderId" binding:"required,max=100"`
+	AuthorType      string  `json:"authorType" binding:"required,max=100,oneof=Organization User"`
+	AuthorId        string  `json:"authorId" binding:"required,max=100"`
+	Version         int     `json:"version"`
+	CreatedAt       string  `json:"createdAt"`
+	Decision        *string `json:"decision"`
 	CreatorUsername string  `json:"creatorUsername"`
 }
 
 type bidDto struct {
-	Id         string `json:"id" db:"id" binding:"max=100"`
-	Name       string `json:"name" db:"name" binding:"required,max=100"`
-	Status     string `json:"status" db:"status" binding:"required,oneof=Created Published Closed"`
-	AuthorType string `json:"authorType" db:"author_type" binding:"required,max=100,oneof=Organization User"`
-	AuthorId   string `json:"authorId" db:"author_id" binding:"required,max=100"`
-	Version    int    `json:"version" db:"version" binding:"required,min=1"`
-	CreatedAt  string `json:"createdAt" db:"created_at" binding:"required"`
+	Id         string `json:"id" binding:"max=100"`
+	Name       string `json:"name" binding:"required,max=100"`
+	Status     string `json:"status" binding:"required,oneof=Created Published Closed"`
+	AuthorType string `json:"authorType" binding:"required,max=100,oneof=Organization User"`
+	AuthorId   string `json:"authorId" binding:"required,max=100"`
+	Version    int    `json:"version" binding:"required,min=1"`
+	CreatedAt  string `json:"createdAt" binding:"required"`
 }
 
-type bidDecision struct {
-	Id       string `json:"id" db:"id" binding:"max=100"`
-	BidId    string `json:"bidId" db:"bid_id" binding:"max=100"`
-	Username string `json:"username" db:"username" binding:"max=50"`
-	Decision string `json:"decision" db:"decision" binding:"oneof=Approved Rejected"`
+// Предложение с решениями согласующих, approvals и rejections - количество согласований и отказов,
+// quorum - необходимое для принятия количество
+type bidDetail struct {
+	Id          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Status      string             `json:"status"`
+	TenderId    string             `json:"tenderId"`
+	AuthorType  string             `json:"authorType"`
+	AuthorId    string             `json:"authorId"`
+	Version     int                `json:"version"`
+	CreatedAt   string             `json:"createdAt"`
+	Decision    *string            `json:"decision"`
+	Decisions   []approverDecision `json:"decisions"`
+	Approvals   int                `json:"approvals"`
+	Rejections  int                `json:"rejections"`
+	Quorum      int                `json:"quorum"`
+}
+
+type approverDecision struct {
+	Username string `json:"username"`
+	Decision string `json:"decision"`
+}
+
+type bidReview struct {
+	Id          string `json:"id"`
+	Description string `json:"description"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+// Версия предложения. changedAt, changedBy, changeKind и comment описывают правку, заменившую версию следующей,
+// у текущей версии не заполняются
+type bidVersion struct {
+	Version     int    `json:"version"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	ChangedAt   string `json:"changedAt,omitempty"`
+	ChangedBy   string `json:"changedBy,omitempty"`
+	ChangeKind  string `json:"changeKind,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+	Broken      bool   `json:"broken,omitempty"`
+}
+
+// Изменяемые поля предложения, непереданные поля не меняются
+type bidEdit struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,min=1,max=500"`
+}
+
+type BidHandler struct {
+	bids *service.BidService
 }
 
 var BidStatusConst []string = []string{"Created", "Published", "Canceled"}
 var BidAuthorType []string = []string{"Organization", "User"}
 var BidDecisionType []string = []string{"Approved", "Rejected"}
 
-const Quorum int = 3
+const MaxFeedbackLength int = 1000
+
+func NewBidHandler(bids *service.BidService) *BidHandler {
+	return &BidHandler{bids: bids}
+}
 
-func InitBidRoutes(routes *gin.RouterGroup) {
+func (h *BidHandler) InitBidRoutes(routes *gin.RouterGroup) {
 	bidRoutes := routes.Group("/bids")
 	//GET
-	bidRoutes.GET("/:id/list", getBidsListTender)
-	bidRoutes.GET("/my", getUserBids)
-	bidRoutes.GET("/:id/status", getStatusBid)
+	bidRoutes.GET("/:id", h.getBid)
+	bidRoutes.GET("/:id/list", h.getBidsListTender)
+	bidRoutes.GET("/my", h.getUserBids)
+	bidRoutes.GET("/:id/status", h.getStatusBid)
+	bidRoutes.GET("/:id/reviews", h.getReviewsOfBid)
+	bidRoutes.GET("/:id/versions", h.getBidVersions)
+	bidRoutes.GET("/:id/versions/:version", h.getBidVersion)
+	bidRoutes.GET("/:id/diff", h.getBidDiff)
 	//POST
-	bidRoutes.POST("/new", createBid)
+	bidRoutes.POST("/new", h.createBid)
 	//PUT
-	bidRoutes.PUT("/:id/status", changeStatusBid)
-	bidRoutes.PUT("/:id/rollback/:version", rollbackVersionBid)
-	bidRoutes.PUT("/:id/submit_decision", SubmitDecisionBid)
+	bidRoutes.PUT("/:id/status", h.changeStatusBid)
+	bidRoutes.PUT("/:id/rollback/:version", h.rollbackVersionBid)
+	bidRoutes.PUT("/:id/submit_decision", h.SubmitDecisionBid)
+	bidRoutes.PUT("/:id/feedback", h.feedbackBid)
 	//PATCH
-	bidRoutes.PATCH("/:id/edit", editBid)
-	/*	bidRoutes.PUT("/:bidId/feedback", feedbackBid)
-		bidRoutes.GET("/:tenderId/reviews", getReviewsOfBid)
-	*/
+	bidRoutes.PATCH("/:id/edit", h.editBid)
+
+}
+
+func newBid(b *repository.Bid) *bid {
+	return &bid{
+		Id:          b.Id,
+		Name:        b.Name,
+		Description: b.Description,
+		Status:      b.Status,
+		TenderId:    b.TenderId,
+		AuthorType:  b.AuthorType,
+		AuthorId:    b.AuthorId,
+		Version:     b.Version,
+		CreatedAt:   b.CreatedAt,
+		Decision:    b.Decision,
+	}
+}
+
+func newBidDetail(d *service.BidDetail) *bidDetail {
+	decisions := make([]approverDecision, 0, len(d.Decisions))
+	for _, decision := range d.Decisions {
+		decisions = append(decisions, approverDecision{Username: decision.Username, Decision: decision.Decision})
+	}
+	return &bidDetail{
+		Id:          d.Bid.Id,
+		Name:        d.Bid.Name,
+		Description: d.Bid.Description,
+		Status:      d.Bid.Status,
+		TenderId:    d.Bid.TenderId,
+		AuthorType:  d.Bid.AuthorType,
+		AuthorId:    d.Bid.AuthorId,
+		Version:     d.Bid.Version,
+		CreatedAt:   d.Bid.CreatedAt,
+		Decision:    d.Bid.Decision,
+		Decisions:   decisions,
+		Approvals:   d.ApprovedCount,
+		Rejections:  d.RejectedCount,
+		Quorum:      d.Quorum,
+	}
+}
+
+func newBidVersion(b *repository.BidVersion) *bidVersion {
+	return &bidVersion{
+		Version:     b.Version,
+		Name:        b.Name,
+		Description: b.Description,
+		Status:      b.Status,
+		ChangedAt:   b.ChangedAt,
+		ChangedBy:   b.ChangedBy,
+		ChangeKind:  b.ChangeKind,
+		Comment:     b.Comment,
+		Broken:      b.Broken,
+	}
+}
+
+func (t *bidEdit) toEdit() service.BidEdit {
+	return service.BidEdit{
+		Name:        t.Name,
+		Description: t.Description,
+	}
+}
 
+func (t *bid) toModel() *repository.Bid {
+	return &repository.Bid{
+		Id:          t.Id,
+		Name:        t.Name,
+		Description: t.Description,
+		Status:      t.Status,
+		TenderId:    t.TenderId,
+		AuthorType:  t.AuthorType,
+		AuthorId:    t.AuthorId,
+		Version:     t.Version,
+		CreatedAt:   t.CreatedAt,
+		Decision:    t.Decision,
+	}
 }
 
 func (t *bid) convertToDto() *bidDto {
@@ -86,35 +215,26 @@ func (t *bid) convertToDto() *bidDto {
 	return &bidDto
 }
 
-// По заданию непонятно какие права должны быть
-func getBidsListTender(c *gin.Context) {
-	log.Info("Чтение параметров")
-	tenderId := c.Param("id")
-	limit := c.Query("limit")
-	offset := c.Query("offset")
-	username := c.Query("username")
-
-	log.Info("Валидация")
-	if limit == "" {
-		limit = "5"
-	}
-	if offset == "" {
-		offset = "0"
+func convertBidsToDto(bids []repository.Bid) []bidDto {
+	bidDtos := make([]bidDto, 0, len(bids))
+	for i := range bids {
+		bidDtos = append(bidDtos, *newBid(&bids[i]).convertToDto())
 	}
+	return bidDtos
+}
 
-	if username == "" {
-		error.GetUserNotPassedError(c)
-		return
-	}
-	err := validator.CheckUserExists(username)
-	if err == sql.ErrNoRows {
-		error.GetUserNotExistsOrIncorrectError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+func (h *BidHandler) getBidsListTender(c *gin.Context) {
+	log.Info("Чтение параметров")
+	tenderId := c.Param("id")
+	statuses := c.QueryArray("status")
+	username := auth.GetUsername(c)
+	page, err := getPage(c)
+	if err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
 
+	log.Info("Валидация")
 	if tenderId == "" {
 		error.GetTenderNotFoundError(c)
 		return
@@ -123,158 +243,96 @@ func getBidsListTender(c *gin.Context) {
 		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	err = validator.CheckTenderExists(tenderId)
-	if err == sql.ErrNoRows {
-		error.GetTenderNotFoundError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
+	for _, status := range statuses {
+		if !slices.Contains(BidStatusConst, status) {
+			error.GetInvalidStatusError(c)
+			return
+		}
 	}
 
-	//По заданию непонятно какие права должны быть
-
 	log.Info("Чтение")
-	query := `SELECT id,
-					name,
-					status,
-					author_type,
-					author_id,
-					version,
-					created_at
-				FROM   bid
-				WHERE tender_id = $1
-				ORDER BY name
-				LIMIT $2 OFFSET $3`
-
-	bids := []bidDto{}
-	err = db.Select(&bids, query, tenderId, limit, offset)
+	bids, total, err := h.bids.ListByTender(username, tenderId, statuses, page)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, bids)
+	setPageHeaders(c, page, total, bids)
+	c.JSON(http.StatusOK, convertBidsToDto(bids))
 }
 
-func getUserBids(c *gin.Context) {
-	limit := c.Query("limit")
-	offset := c.Query("offset")
-	username := c.Query("username")
-
-	log.Info("Валидация")
-	if limit == "" {
-		limit = "5"
-	}
-	if offset == "" {
-		offset = "0"
-	}
-	if username == "" {
-		error.GetUserNotPassedError(c)
-		return
-	}
-	err := validator.CheckUserExists(username)
-	if err == sql.ErrNoRows {
-		error.GetUserNotExistsOrIncorrectError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+func (h *BidHandler) getUserBids(c *gin.Context) {
+	page, err := getPage(c)
+	if err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
+	username := auth.GetUsername(c)
 
 	log.Info("Чтение")
-	query := `SELECT b.id,
-					b.name,
-					b.status,
-					b.author_type,
-					b.author_id,
-					b.version,
-					b.created_at
-				FROM bid b
-				WHERE (author_type = 'User' AND exists(select 1
-												from employee emp
-												where emp.id = b.author_id and emp.username= $1)
-					OR b.author_type = 'Organization'
-						AND EXISTS(SELECT 1
-									FROM organization_responsible org_r
-										JOIN employee emp ON emp.id = org_r.user_id AND emp.username = $1
-									WHERE org_r.organization_id = b.author_id))
-				ORDER BY name
-				LIMIT $2 OFFSET $3`
-	bids := []bidDto{}
-	err = db.Select(&bids, query, username, limit, offset)
+	bids, total, err := h.bids.ListByMember(username, page)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, bids)
+	setPageHeaders(c, page, total, bids)
+	c.JSON(http.StatusOK, convertBidsToDto(bids))
 }
 
-func getStatusBid(c *gin.Context) {
+func (h *BidHandler) getBid(c *gin.Context) {
 	log.Info("Чтение параметров")
 	bidId := c.Param("id")
-	username := c.Query("username")
+	username := auth.GetUsername(c)
 
 	log.Info("Валидация")
-	if bidId == "" {
-		error.GetBidIdNotPassedError(c)
-		return
-	}
 	if err := uuid.Validate(bidId); err != nil {
 		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	err := validator.CheckBidExists(bidId)
-	if err == sql.ErrNoRows {
-		error.GetBidNotFoundError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
 
-	if username == "" {
-		error.GetUserNotPassedError(c)
-		return
-	}
-	err = validator.CheckUserExists(username)
-	if err == sql.ErrNoRows {
-		error.GetUserNotExistsOrIncorrectError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+	log.Info("Чтение данных")
+	detail, err := h.bids.GetDetail(username, bidId)
+	if err != nil {
+		getServiceError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, newBidDetail(detail))
+}
+
+func (h *BidHandler) getStatusBid(c *gin.Context) {
+	log.Info("Чтение параметров")
+	bidId := c.Param("id")
+	username := auth.GetUsername(c)
 
-	log.Info("Авторизация")
-	err = auth.CheckUserViewBid(username, bidId)
-	if err == sql.ErrNoRows {
-		error.GetUserNotViewBidError(c)
+	log.Info("Валидация")
+	if bidId == "" {
+		error.GetBidIdNotPassedError(c)
 		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+	}
+	if err := uuid.Validate(bidId); err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
 
 	log.Info("Чтение данных")
-	var status string
-	err = db.Get(&status, "SELECT status FROM bid WHERE id = $1", bidId)
+	status, err := h.bids.GetStatus(username, bidId)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, status)
 }
 
-func createBid(c *gin.Context) {
+func (h *BidHandler) createBid(c *gin.Context) {
 	log.Info("Чтение параметров")
-	someBid := bid{Version: 1, CreatedAt: time.Now().Format(time.RFC3339), Status: "Created"}
+	var someBid bid
 	err := c.BindJSON(&someBid)
 	if err != nil {
 		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
+	someBid.CreatorUsername = auth.GetUsername(c)
 
 	log.Info("Валидация")
 	if err := uuid.Validate(someBid.TenderId); err != nil {
@@ -285,78 +343,24 @@ func createBid(c *gin.Context) {
 		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	err = validator.CheckUserExists(someBid.CreatorUsername)
-	if err == sql.ErrNoRows {
-		error.GetUserNotExistsOrIncorrectError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
-
-	err = validator.CheckTenderExists(someBid.TenderId)
-	if err == sql.ErrNoRows {
-		error.GetTenderNotFoundError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
-
-	log.Info("Авторизация")
-	err = auth.CheckUserCanManageBid(someBid.CreatorUsername, someBid.AuthorType, someBid.AuthorId)
-	if err == sql.ErrNoRows {
-		error.GetUserNotAuthorOrResponsibleOrganizationError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
 
 	log.Info("Создание")
-	var lastInsertId string
-	tx, err := db.Beginx()
-	if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
-	defer tx.Rollback()
-	query := `INSERT INTO bid
-							(name,
-							description,
-							status,
-							tender_id,
-							author_type,
-							author_id,
-							version,
-							created_at)
-				VALUES     ($1,
-							$2,
-							$3,
-							$4,
-							$5,
-							$6,
-							$7,
-							$8)
-						RETURNING id`
-	err = tx.QueryRow(query, someBid.Name, someBid.Description, someBid.Status,
-		someBid.TenderId, someBid.AuthorType, someBid.AuthorId,
-		someBid.Version, someBid.CreatedAt).Scan(&lastInsertId)
+	model := someBid.toModel()
+	err = h.bids.Create(audit.GetActor(c), model)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
-	tx.Commit()
-	someBid.Id = lastInsertId
 
-	c.JSON(http.StatusOK, someBid.convertToDto())
+	c.JSON(http.StatusOK, newBid(model).convertToDto())
 }
 
-func changeStatusBid(c *gin.Context) {
+func (h *BidHandler) changeStatusBid(c *gin.Context) {
 	log.Info("Чтение параметров")
 
 	status := c.Query("status")
-	username := c.Query("username")
+	comment := c.Query("comment")
+	actor := audit.GetActor(c)
 	bidId := c.Param("id")
 
 	log.Info("Валидация")
@@ -368,6 +372,10 @@ func changeStatusBid(c *gin.Context) {
 		error.GetInvalidStatusError(c)
 		return
 	}
+	if utf8.RuneCountInString(comment) > MaxCommentLength {
+		error.GetInvalidCommentError(c)
+		return
+	}
 
 	if bidId == "" {
 		error.GetBidIdNotPassedError(c)
@@ -377,197 +385,141 @@ func changeStatusBid(c *gin.Context) {
 		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	err := validator.CheckBidExists(bidId)
-	if err == sql.ErrNoRows {
-		error.GetBidNotFoundError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
-
-	if username == "" {
-		error.GetUserNotPassedError(c)
-		return
-	}
-	err = validator.CheckUserExists(username)
-	if err == sql.ErrNoRows {
-		error.GetUserNotExistsOrIncorrectError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
 
-	log.Info("Чтение данных")
-	bid := bid{}
-	err = db.Get(&bid, `SELECT id,
-								name,
-								status,
-								tender_id,
-								author_type,
-								author_id,
-								version,
-								created_at
-							FROM bid WHERE id = $1`, bidId)
+	log.Info("Изменение")
+	bid, err := h.bids.ChangeStatus(actor, bidId, status, comment)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
 
-	log.Info("Авторизация")
-	err = auth.CheckUserCanManageBid(username, bid.AuthorType, bid.AuthorId)
-	if err == sql.ErrNoRows {
-		error.GetUserNotAuthorOrResponsibleOrganizationError(c)
+	c.JSON(http.StatusOK, newBid(bid).convertToDto())
+}
+
+func (h *BidHandler) editBid(c *gin.Context) {
+	log.Info("Чтение параметров")
+	bidId := c.Param("id")
+	comment := c.Query("comment")
+	actor := audit.GetActor(c)
+
+	log.Info("Валидация")
+	if bidId == "" {
+		error.GetTenderIdNotPassedError(c)
 		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+	}
+	if err := uuid.Validate(bidId); err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-
-	log.Info("Изменение")
-	tx, err := db.Beginx()
-	if err != nil {
-		error.GetInternalServerError(c, err)
+	if utf8.RuneCountInString(comment) > MaxCommentLength {
+		error.GetInvalidCommentError(c)
 		return
 	}
-	defer tx.Rollback()
-	_, err = tx.Exec("UPDATE bid SET status = $1 WHERE id = $2", status, bid.Id)
+
+	var edit bidEdit
+	err := c.BindJSON(&edit)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	tx.Commit()
 
-	log.Info("Чтение данных")
-	err = db.Get(&bid, `SELECT id,
-								name,
-								status,
-								tender_id,
-								author_type,
-								author_id,
-								version,
-								created_at
-							FROM bid WHERE id = $1`, bid.Id)
+	log.Info("Изменение")
+	bid, err := h.bids.Edit(actor, bidId, edit.toEdit(), comment)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, bid.convertToDto())
+	c.JSON(http.StatusOK, newBid(bid).convertToDto())
 }
-
-func editBid(c *gin.Context) {
+func (h *BidHandler) rollbackVersionBid(c *gin.Context) {
 	log.Info("Чтение параметров")
 	bidId := c.Param("id")
-	username := c.Query("username")
+	comment := c.Query("comment")
+	actor := audit.GetActor(c)
 
 	log.Info("Валидация")
 	if bidId == "" {
-		error.GetTenderIdNotPassedError(c)
+		error.GetBidIdNotPassedError(c)
 		return
 	}
 	if err := uuid.Validate(bidId); err != nil {
 		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	err := validator.CheckBidExists(bidId)
-	if err == sql.ErrNoRows {
-		error.GetBidNotFoundError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
 
-	if username == "" {
-		error.GetUserNotPassedError(c)
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	err = validator.CheckUserExists(username)
-	if err == sql.ErrNoRows {
-		error.GetUserNotExistsOrIncorrectError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+	if utf8.RuneCountInString(comment) > MaxCommentLength {
+		error.GetInvalidCommentError(c)
 		return
 	}
 
-	log.Info("Чтение данных")
-	bid := bid{}
-	err = db.Get(&bid, `SELECT id,
-								name,
-								status,
-								tender_id,
-								author_type,
-								author_id,
-								version,
-								created_at
-							FROM bid WHERE id = $1`, bidId)
+	log.Info("Изменение")
+	bid, err := h.bids.Rollback(actor, bidId, version, comment)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
 
-	err = c.BindJSON(&bid)
-	if err != nil {
-		error.GetInvalidRequestFormatOrParametersError(c, err)
-		return
-	}
+	c.JSON(http.StatusOK, newBid(bid).convertToDto())
+}
+
+// Расширенный процесс согласования
+func (h *BidHandler) SubmitDecisionBid(c *gin.Context) {
+	log.Info("Чтение параметров")
+	bidId := c.Param("id")
+	actor := audit.GetActor(c)
+	decision := c.Query("decision")
 
-	log.Info("Авторизация")
-	err = auth.CheckUserCanManageBid(username, bid.AuthorType, bid.AuthorId)
-	if err == sql.ErrNoRows {
-		error.GetUserNotAuthorOrResponsibleOrganizationError(c)
+	log.Info("Валидация")
+	if decision == "" {
+		error.GetDecisionNotPassedError(c)
 		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+	}
+	if !slices.Contains(BidDecisionType, decision) {
+		error.GetInvalidDecisionError(c)
 		return
 	}
 
-	log.Info("Изменение")
-	query := `UPDATE bid
-				SET    name = :name,
-						description = :description
-				WHERE  id = :id`
-
-	tx, err := db.Beginx()
-	if err != nil {
-		error.GetInternalServerError(c, err)
+	if bidId == "" {
+		error.GetBidIdNotPassedError(c)
 		return
 	}
-	defer tx.Rollback()
-
-	_, err = tx.NamedExec(query, bid)
-	if err != nil {
-		error.GetInternalServerError(c, err)
+	if err := uuid.Validate(bidId); err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	tx.Commit()
 
-	log.Info("Чтение данных")
-	err = db.Get(&bid, `SELECT id,
-								name,
-								status,
-								tender_id,
-								author_type,
-								author_id,
-								version,
-								created_at
-							FROM bid WHERE id = $1`, bid.Id)
+	log.Info("Изменение")
+	bid, err := h.bids.SubmitDecision(actor, bidId, decision)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, bid.convertToDto())
+	c.JSON(http.StatusOK, newBid(bid).convertToDto())
 }
-func rollbackVersionBid(c *gin.Context) {
+
+func (h *BidHandler) feedbackBid(c *gin.Context) {
 	log.Info("Чтение параметров")
 	bidId := c.Param("id")
-	username := c.Query("username")
+	feedback := c.Query("bidFeedback")
+	actor := audit.GetActor(c)
 
 	log.Info("Валидация")
+	if feedback == "" {
+		error.GetFeedbackNotPassedError(c)
+		return
+	}
+	if utf8.RuneCountInString(feedback) > MaxFeedbackLength {
+		error.GetInvalidFeedbackError(c)
+		return
+	}
+
 	if bidId == "" {
 		error.GetBidIdNotPassedError(c)
 		return
@@ -576,127 +528,92 @@ func rollbackVersionBid(c *gin.Context) {
 		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	err := validator.CheckBidExists(bidId)
-	if err == sql.ErrNoRows {
-		error.GetBidNotFoundError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+
+	log.Info("Создание")
+	bid, err := h.bids.Feedback(actor, bidId, feedback)
+	if err != nil {
+		getServiceError(c, err)
 		return
 	}
 
-	version, err := strconv.Atoi(c.Param("version"))
+	c.JSON(http.StatusOK, newBid(bid).convertToDto())
+}
+
+func (h *BidHandler) getReviewsOfBid(c *gin.Context) {
+	log.Info("Чтение параметров")
+	tenderId := c.Param("id")
+	authorUsername := c.Query("authorUsername")
+	requesterUsername := auth.GetUsername(c)
+	limit, offset, err := getPagination(c)
 	if err != nil {
 		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
 
-	if username == "" {
-		error.GetUserNotPassedError(c)
+	log.Info("Валидация")
+	if tenderId == "" {
+		error.GetTenderIdNotPassedError(c)
 		return
 	}
-	err = validator.CheckUserExists(username)
-	if err == sql.ErrNoRows {
-		error.GetUserNotExistsOrIncorrectError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+	if err := uuid.Validate(tenderId); err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
 
-	log.Info("Чтение данных")
-	bid := bid{}
-	err = db.Get(&bid, `SELECT id,
-								name,
-								status,
-								tender_id,
-								author_type,
-								author_id,
-								version,
-								created_at
-							FROM bid WHERE id = $1`, bidId)
-	if err != nil {
-		error.GetInternalServerError(c, err)
+	if authorUsername == "" {
+		error.GetAuthorNotPassedError(c)
 		return
 	}
 
-	log.Info("Авторизация")
-	err = auth.CheckUserCanManageBid(username, bid.AuthorType, bid.AuthorId)
-	if err == sql.ErrNoRows {
-		error.GetUserNotAuthorOrResponsibleOrganizationError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+	log.Info("Чтение")
+	feedback, err := h.bids.ListAuthorFeedback(requesterUsername, tenderId, authorUsername, limit, offset)
+	if err != nil {
+		getServiceError(c, err)
 		return
 	}
 
-	if version >= bid.Version {
-		error.GetInvalidVersionError(c)
-		return
+	reviews := make([]bidReview, 0, len(feedback))
+	for _, f := range feedback {
+		reviews = append(reviews, bidReview{Id: f.Id, Description: f.Description, CreatedAt: f.CreatedAt})
 	}
+	c.JSON(http.StatusOK, reviews)
+}
 
-	log.Info("Чтение данных")
-	var params string
-	err = db.Get(&params, `SELECT params 
-							FROM bid_version_hist 
-							WHERE bid_id = $1 AND version = $2`, bid.Id, version)
-	if err != nil {
-		error.GetVersionNotFoundError(c)
+func (h *BidHandler) getBidVersions(c *gin.Context) {
+	log.Info("Чтение параметров")
+	bidId := c.Param("id")
+	username := auth.GetUsername(c)
+
+	log.Info("Валидация")
+	if bidId == "" {
+		error.GetBidIdNotPassedError(c)
 		return
 	}
-	json.Unmarshal([]byte(params), &bid)
-
-	log.Info("Изменение")
-	query := `UPDATE bid
-				SET    name = :name,
-						description = :description
-				WHERE  id = :id`
-
-	tx := db.MustBegin()
-	_, err = tx.NamedExec(query, &bid)
-	if err != nil {
-		error.GetInternalServerError(c, err)
+	if err := uuid.Validate(bidId); err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	tx.Commit()
-
-	log.Info(bid.Id)
 
 	log.Info("Чтение данных")
-	err = db.Get(&bid, `SELECT id,
-								name,
-								status,
-								tender_id,
-								author_type,
-								author_id,
-								version,
-								created_at
-							FROM bid WHERE id = $1`, bid.Id)
+	versions, err := h.bids.ListVersions(username, bidId)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, bid.convertToDto())
+	bidVersions := make([]bidVersion, 0, len(versions))
+	for i := range versions {
+		bidVersions = append(bidVersions, *newBidVersion(&versions[i]))
+	}
+	c.JSON(http.StatusOK, bidVersions)
 }
 
-// Расширенный процесс согласования
-func SubmitDecisionBid(c *gin.Context) {
+func (h *BidHandler) getBidVersion(c *gin.Context) {
 	log.Info("Чтение параметров")
 	bidId := c.Param("id")
-	username := c.Query("username")
-	decision := c.Query("decision")
+	username := auth.GetUsername(c)
 
 	log.Info("Валидация")
-	if decision == "" {
-		error.GetDecisionNotPassedError(c)
-		return
-	}
-	if !slices.Contains(BidDecisionType, decision) {
-		error.GetInvalidDecisionError(c)
-		return
-	}
-
 	if bidId == "" {
 		error.GetBidIdNotPassedError(c)
 		return
@@ -705,127 +622,54 @@ func SubmitDecisionBid(c *gin.Context) {
 		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	err := validator.CheckBidExists(bidId)
-	if err == sql.ErrNoRows {
-		error.GetBidNotFoundError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
 
-	if username == "" {
-		error.GetUserNotPassedError(c)
-		return
-	}
-	err = validator.CheckUserExists(username)
-	if err == sql.ErrNoRows {
-		error.GetUserNotExistsOrIncorrectError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
 
 	log.Info("Чтение данных")
-	bid := bid{}
-	err = db.Get(&bid, `SELECT id,
-								name,
-								status,
-								tender_id,
-								author_type,
-								author_id,
-								version,
-								created_at,
-								decision
-							FROM bid WHERE id = $1`, bidId)
+	bidVersion, err := h.bids.GetVersion(username, bidId, version)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
 
-	if bid.Decision != nil {
-		error.GetBidAlreadyHasDecisionError(c)
-		return
-	}
+	c.JSON(http.StatusOK, newBidVersion(bidVersion))
+}
 
-	var decisionCnt int
-	err = db.Get(&decisionCnt, `SELECT COUNT(*)
-							FROM bid_decision
-							WHERE bid_id = $1 AND username=$2`,
-		bid.Id, username)
-	if err != nil {
-		error.GetInternalServerError(c, err)
+func (h *BidHandler) getBidDiff(c *gin.Context) {
+	log.Info("Чтение параметров")
+	bidId := c.Param("id")
+	username := auth.GetUsername(c)
+
+	log.Info("Валидация")
+	if bidId == "" {
+		error.GetBidIdNotPassedError(c)
 		return
 	}
-	if decisionCnt >= 1 {
-		error.GetUserHasDecisionForBidError(c)
+	if err := uuid.Validate(bidId); err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
 
-	log.Info("Авторизация")
-	err = auth.CheckUserCanApproveBid(username, bid.TenderId)
-	if err == sql.ErrNoRows {
-		error.GetUserNotResponsibleOrganizationError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+	if c.Query("from") == "" {
+		error.GetVersionNotPassedError(c)
 		return
 	}
-
-	log.Info("Изменение")
-	var lastInsertId string
-	tx, err := db.Beginx()
+	from, to, unified, err := getDiffParams(c)
 	if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
-	defer tx.Rollback()
-	err = tx.QueryRow(`INSERT INTO bid_decision
-									(bid_id,
-									username,
-									decision)
-						VALUES     ($1,
-									$2,
-									$3)
-						RETURNING id`, bid.Id,
-		username, decision).Scan(&lastInsertId)
-	if err != nil {
-		error.GetInternalServerError(c, err)
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
 
-	if decision == "Rejected" {
-
-		_, err = tx.Exec("UPDATE bid SET decision = $1 WHERE id = $2", decision, bid.Id)
-		if err != nil {
-			error.GetInternalServerError(c, err)
-			return
-		}
-	} else {
-		err = tx.Get(&decisionCnt, `SELECT COUNT(*)
-							FROM bid_decision
-							WHERE bid_id = $1 AND decision = 'Approved'`,
-			bid.Id)
-		if err != nil {
-			error.GetInternalServerError(c, err)
-			return
-		}
-		log.Info(decisionCnt)
-		if decisionCnt >= Quorum {
-			_, err = tx.Exec("UPDATE bid SET decision = $1 WHERE id = $2", decision, bid.Id)
-			if err != nil {
-				error.GetInternalServerError(c, err)
-				return
-			}
-			_, err = tx.Exec("UPDATE tender SET status = $1 WHERE id = $2", "Closed", bid.TenderId)
-			if err != nil {
-				error.GetInternalServerError(c, err)
-				return
-			}
-		}
+	log.Info("Чтение данных")
+	diff, err := h.bids.Diff(username, bidId, from, to)
+	if err != nil {
+		getServiceError(c, err)
+		return
 	}
-	tx.Commit()
 
-	c.JSON(http.StatusOK, bid.convertToDto())
+	c.JSON(http.StatusOK, newVersionDiff(diff, unified))
 }
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"io"
+	"time"
+
+	"avitoTask/internal/auth"
+	"avitoTask/internal/error"
+	"avitoTask/internal/events"
+	"avitoTask/internal/repository"
+	"avitoTask/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// Интервал отправки комментария-пинга, чтобы прокси не закрывали простаивающее соединение
+const EventKeepAliveInterval = 15 * time.Second
+
+type EventHandler struct {
+	broker  *events.Broker
+	auth    *auth.Auth
+	tenders *service.TenderService
+}
+
+func NewEventHandler(broker *events.Broker, auth *auth.Auth, tenders *service.TenderService) *EventHandler {
+	return &EventHandler{broker: broker, auth: auth, tenders: tenders}
+}
+
+func (h *EventHandler) InitEventRoutes(routes *gin.RouterGroup) {
+	//GET
+	routes.GET("/events", h.streamEvents)
+}
+
+// Событие доставляется, только если пользователь видит тендер или предложение в состоянии из события,
+// а не в текущем: к моменту доставки тендер мог закрыться. Событие тендера видно, если тендер был виден
+// до или после смены статуса, поэтому о закрытии опубликованного тендера узнают и подавшие предложения
+func (h *EventHandler) canView(username string, event events.Event) bool {
+	if event.BidId != "" {
+		bid := &repository.Bid{
+			Id:         event.BidId,
+			TenderId:   event.TenderId,
+			Status:     event.Status,
+			AuthorType: event.AuthorType,
+			AuthorId:   event.AuthorId,
+		}
+		return h.auth.CheckUserViewBidState(username, bid) == nil
+	}
+	for _, status := range []string{event.PreviousStatus, event.Status} {
+		tender := &repository.Tender{Id: event.TenderId, Status: status, OrganizationId: event.OrganizationId}
+		if h.auth.CheckUserViewTenderState(username, tender) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *EventHandler) streamEvents(c *gin.Context) {
+	log.Info("Чтение параметров")
+	tenderId := c.Query("tenderId")
+	username := auth.GetUsername(c)
+
+	log.Info("Валидация")
+	if tenderId != "" {
+		if err := uuid.Validate(tenderId); err != nil {
+			error.GetInvalidRequestFormatOrParametersError(c, err)
+			return
+		}
+		if _, err := h.tenders.GetStatus(username, tenderId); err != nil {
+			getServiceError(c, err)
+			return
+		}
+	}
+
+	log.Info("Подписка на события")
+	subscription, unsubscribe := h.broker.Subscribe()
+	defer unsubscribe()
+	keepAlive := time.NewTicker(EventKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case event, ok := <-subscription:
+			if !ok {
+				return false
+			}
+			if tenderId != "" && event.TenderId != tenderId || !h.canView(username, event) {
+				return true
+			}
+			c.SSEvent(event.Type, event)
+			return true
+		}
+	})
+}
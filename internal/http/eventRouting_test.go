@@ -0,0 +1,77 @@
+package http
+
+import (
+	"encoding/json"
+	nethttp "net/http"
+	"testing"
+
+	"avitoTask/internal/events"
+)
+
+// События из outbox в порядке записи
+func (s *testServer) outboxEvents() []events.Event {
+	s.t.Helper()
+	pending, err := s.store.Outbox.ListPending("test", 100)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	result := make([]events.Event, 0, len(pending))
+	for _, outboxEvent := range pending {
+		var event events.Event
+		err := json.Unmarshal([]byte(outboxEvent.Payload), &event)
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		result = append(result, event)
+	}
+	return result
+}
+
+func findEvent(t *testing.T, all []events.Event, eventType, tenderId string) events.Event {
+	t.Helper()
+	for _, event := range all {
+		if event.Type == eventType && event.TenderId == tenderId {
+			return event
+		}
+	}
+	t.Fatalf("событие %s тендера %s не записано", eventType, tenderId)
+	return events.Event{}
+}
+
+// Видимость событий проверяется по состоянию из события: о закрытии тендера узнают подавшие
+// предложения, а о чужих предложениях - только организация тендера и сторона автора
+func TestEventsFilteredByEventState(t *testing.T) {
+	s := newTestServer(t)
+	h := &EventHandler{auth: s.auth}
+
+	tenderId := s.publishedTender()
+	bidId := s.draftBid("bob", testBobId, tenderId)
+	s.mustDo("bob", nethttp.MethodPut, "/bids/"+bidId+"/status?status=Published", nil, nil)
+	s.mustDo("alice", nethttp.MethodPut, "/bids/"+bidId+"/submit_decision?decision=Approved", nil, nil)
+
+	var draft tenderDto
+	s.mustDo("alice", nethttp.MethodPost, "/tenders/new", map[string]string{
+		"name":           "Черновик",
+		"description":    "Описание",
+		"serviceType":    "Delivery",
+		"organizationId": testOrganizationId,
+	}, &draft)
+	s.mustDo("alice", nethttp.MethodPut, "/tenders/"+draft.Id+"/status?status=Closed", nil, nil)
+
+	all := s.outboxEvents()
+	for _, check := range []struct {
+		event   events.Event
+		viewers map[string]bool
+	}{
+		{findEvent(t, all, events.BidPublished, tenderId), map[string]bool{"alice": true, "bob": true, "carol": false, "dave": false}},
+		{findEvent(t, all, events.BidDecision, tenderId), map[string]bool{"alice": true, "bob": true, "carol": false, "dave": false}},
+		{findEvent(t, all, events.TenderClosed, tenderId), map[string]bool{"alice": true, "bob": true, "carol": true}},
+		{findEvent(t, all, events.TenderStatusChanged, draft.Id), map[string]bool{"alice": true, "bob": false, "dave": false}},
+	} {
+		for username, expected := range check.viewers {
+			if h.canView(username, check.event) != expected {
+				t.Errorf("%s: доставка события %s %+v, ожидалась %v", username, check.event.Type, check.event, expected)
+			}
+		}
+	}
+}
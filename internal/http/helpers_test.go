@@ -0,0 +1,138 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	validator "avitoTask/internal"
+	"avitoTask/internal/auth"
+	"avitoTask/internal/events"
+	"avitoTask/internal/outbox"
+	"avitoTask/internal/repository"
+	"avitoTask/internal/repository/memory"
+	"avitoTask/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Секрет подписи токенов в проверках, не короче auth.MinSecretLength
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+const (
+	testOrganizationId      = "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"
+	testOtherOrganizationId = "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"
+	// alice - владелец testOrganizationId, bob и carol - сотрудники без ролей, dave - владелец testOtherOrganizationId
+	testAliceId = "11111111-1111-1111-1111-111111111111"
+	testBobId   = "22222222-2222-2222-2222-222222222222"
+	testCarolId = "33333333-3333-3333-3333-333333333333"
+	testDaveId  = "44444444-4444-4444-4444-444444444444"
+)
+
+type testServer struct {
+	t      *testing.T
+	store  *repository.Store
+	auth   *auth.Auth
+	routes *gin.Engine
+}
+
+func newTestServer(t *testing.T) *testServer {
+	gin.SetMode(gin.TestMode)
+	store := memory.NewStore(&memory.Seed{
+		Employees: []memory.Employee{
+			{Id: testAliceId, Username: "alice"},
+			{Id: testBobId, Username: "bob"},
+			{Id: testCarolId, Username: "carol"},
+			{Id: testDaveId, Username: "dave"},
+		},
+		Organizations: []memory.Organization{
+			{Id: testOrganizationId, Name: "Org"},
+			{Id: testOtherOrganizationId, Name: "Other"},
+		},
+		OrganizationResponsibles: []memory.OrganizationResponsible{
+			{OrganizationId: testOrganizationId, UserId: testAliceId},
+			{OrganizationId: testOtherOrganizationId, UserId: testDaveId},
+		},
+	})
+	authorization := auth.NewAuth(store, testSecret)
+	dispatcher := webhook.NewDispatcher(store, webhook.Config{Retry: webhook.RetryPolicy{MaxAttempts: 1}, PollInterval: time.Second})
+	relay := outbox.NewRelay(store, time.Second)
+	routes := InitRoutes(store, validator.NewValidator(store), authorization, events.NewBroker(), dispatcher, relay)
+	return &testServer{t: t, store: store, auth: authorization, routes: routes}
+}
+
+// Выполняет запрос от имени username и возвращает ответ целиком, если нужны заголовки
+func (s *testServer) serve(username, method, path string, body any) *httptest.ResponseRecorder {
+	s.t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		reader = bytes.NewReader(content)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	request := httptest.NewRequest(method, "/api"+path, reader)
+	token, err := s.auth.IssueToken(username, time.Hour)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	s.routes.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// Выполняет запрос от имени username и разбирает ответ в result, если он передан
+func (s *testServer) do(username, method, path string, body any, result any) int {
+	s.t.Helper()
+	recorder := s.serve(username, method, path, body)
+	if result != nil && recorder.Code == nethttp.StatusOK {
+		err := json.Unmarshal(recorder.Body.Bytes(), result)
+		if err != nil {
+			s.t.Fatalf("%s %s: %v: %s", method, path, err, recorder.Body.String())
+		}
+	}
+	return recorder.Code
+}
+
+// Выполняет запрос, который должен завершиться статусом 200
+func (s *testServer) mustDo(username, method, path string, body any, result any) {
+	s.t.Helper()
+	if code := s.do(username, method, path, body, result); code != nethttp.StatusOK {
+		s.t.Fatalf("%s %s: статус %d", method, path, code)
+	}
+}
+
+// Опубликованный тендер организации testOrganizationId
+func (s *testServer) publishedTender() string {
+	s.t.Helper()
+	var tender tenderDto
+	s.mustDo("alice", nethttp.MethodPost, "/tenders/new", map[string]string{
+		"name":           "Тендер",
+		"description":    "Описание",
+		"serviceType":    "Delivery",
+		"organizationId": testOrganizationId,
+	}, &tender)
+	s.mustDo("alice", nethttp.MethodPut, "/tenders/"+tender.Id+"/status?status=Published", nil, nil)
+	return tender.Id
+}
+
+// Предложение сотрудника authorId в статусе Created
+func (s *testServer) draftBid(username, authorId, tenderId string) string {
+	s.t.Helper()
+	var bid bidDto
+	s.mustDo(username, nethttp.MethodPost, "/bids/new", map[string]string{
+		"name":        "Черновик предложения",
+		"description": "Описание",
+		"tenderId":    tenderId,
+		"authorType":  "User",
+		"authorId":    authorId,
+	}, &bid)
+	return bid.Id
+}
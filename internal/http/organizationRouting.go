@@ -0,0 +1,212 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"avitoTask/internal/audit"
+	"avitoTask/internal/auth"
+	apiError "avitoTask/internal/error"
+	"avitoTask/internal/repository"
+	"avitoTask/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+type organizationRole struct {
+	Username  string  `json:"username"`
+	Role      string  `json:"role"`
+	GrantedBy *string `json:"grantedBy"`
+	GrantedAt *string `json:"grantedAt"`
+}
+
+// Кворум согласований организации, isDefault - для организации не задан свой кворум.
+// effectiveQuorum - кворум, по которому принимаются решения: не больше числа согласующих
+type organizationQuorum struct {
+	Quorum          int  `json:"quorum"`
+	IsDefault       bool `json:"isDefault"`
+	EffectiveQuorum int  `json:"effectiveQuorum"`
+}
+
+type OrganizationHandler struct {
+	organizations *service.OrganizationService
+}
+
+func NewOrganizationHandler(organizations *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{organizations: organizations}
+}
+
+func (h *OrganizationHandler) InitOrganizationRoutes(routes *gin.RouterGroup) {
+	organizationRoutes := routes.Group("/organizations")
+	//GET
+	organizationRoutes.GET("/:organizationId/roles", h.getOrganizationRoles)
+	organizationRoutes.GET("/:organizationId/quorum", h.getOrganizationQuorum)
+	//POST
+	organizationRoutes.POST("/:organizationId/roles", h.grantOrganizationRole)
+	//PUT
+	organizationRoutes.PUT("/:organizationId/quorum", h.setOrganizationQuorum)
+	//DELETE
+	organizationRoutes.DELETE("/:organizationId/roles", h.revokeOrganizationRole)
+}
+
+func newOrganizationRole(r *repository.OrganizationRole) *organizationRole {
+	return &organizationRole{
+		Username:  r.Username,
+		Role:      r.Role,
+		GrantedBy: r.GrantedBy,
+		GrantedAt: r.GrantedAt,
+	}
+}
+
+func newOrganizationQuorum(q *service.QuorumDetail) *organizationQuorum {
+	return &organizationQuorum{
+		Quorum:          q.Quorum,
+		IsDefault:       q.IsDefault,
+		EffectiveQuorum: q.Effective,
+	}
+}
+
+func (h *OrganizationHandler) getOrganizationRoles(c *gin.Context) {
+	log.Info("Чтение параметров")
+	organizationId := c.Param("organizationId")
+	username := auth.GetUsername(c)
+
+	log.Info("Валидация")
+	if err := uuid.Validate(organizationId); err != nil {
+		apiError.GetInvalidRequestFormatOrParametersError(c, err)
+		return
+	}
+
+	log.Info("Чтение")
+	roles, err := h.organizations.ListRoles(username, organizationId)
+	if err != nil {
+		getServiceError(c, err)
+		return
+	}
+
+	organizationRoles := make([]organizationRole, 0, len(roles))
+	for i := range roles {
+		organizationRoles = append(organizationRoles, *newOrganizationRole(&roles[i]))
+	}
+	c.JSON(http.StatusOK, organizationRoles)
+}
+
+func (h *OrganizationHandler) grantOrganizationRole(c *gin.Context) {
+	log.Info("Чтение параметров")
+	organizationId := c.Param("organizationId")
+	employeeUsername := c.Query("username")
+	role := c.Query("role")
+	actor := audit.GetActor(c)
+
+	log.Info("Валидация")
+	if !validateOrganizationRoleParams(c, organizationId, employeeUsername, role) {
+		return
+	}
+
+	log.Info("Создание")
+	grantedRole, err := h.organizations.GrantRole(actor, organizationId, employeeUsername, role)
+	if err != nil {
+		getServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newOrganizationRole(grantedRole))
+}
+
+func (h *OrganizationHandler) revokeOrganizationRole(c *gin.Context) {
+	log.Info("Чтение параметров")
+	organizationId := c.Param("organizationId")
+	employeeUsername := c.Query("username")
+	role := c.Query("role")
+	actor := audit.GetActor(c)
+
+	log.Info("Валидация")
+	if !validateOrganizationRoleParams(c, organizationId, employeeUsername, role) {
+		return
+	}
+
+	log.Info("Удаление")
+	err := h.organizations.RevokeRole(actor, organizationId, employeeUsername, role)
+	if err != nil {
+		getServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, organizationRole{Username: employeeUsername, Role: role})
+}
+
+func (h *OrganizationHandler) getOrganizationQuorum(c *gin.Context) {
+	log.Info("Чтение параметров")
+	organizationId := c.Param("organizationId")
+	username := auth.GetUsername(c)
+
+	log.Info("Валидация")
+	if err := uuid.Validate(organizationId); err != nil {
+		apiError.GetInvalidRequestFormatOrParametersError(c, err)
+		return
+	}
+
+	log.Info("Чтение")
+	quorum, err := h.organizations.GetQuorum(username, organizationId)
+	if err != nil {
+		getServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newOrganizationQuorum(quorum))
+}
+
+func (h *OrganizationHandler) setOrganizationQuorum(c *gin.Context) {
+	log.Info("Чтение параметров")
+	organizationId := c.Param("organizationId")
+	quorumParam := c.Query("quorum")
+	actor := audit.GetActor(c)
+
+	log.Info("Валидация")
+	if err := uuid.Validate(organizationId); err != nil {
+		apiError.GetInvalidRequestFormatOrParametersError(c, err)
+		return
+	}
+	if quorumParam == "" {
+		apiError.GetQuorumNotPassedError(c)
+		return
+	}
+	quorum, err := strconv.Atoi(quorumParam)
+	if err != nil || quorum < 1 {
+		apiError.GetInvalidQuorumError(c)
+		return
+	}
+
+	log.Info("Изменение")
+	updated, err := h.organizations.SetQuorum(actor, organizationId, quorum)
+	if err != nil {
+		getServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newOrganizationQuorum(updated))
+}
+
+// Проверяет формат параметров, существование организации и сотрудника проверяет сервис
+func validateOrganizationRoleParams(c *gin.Context, organizationId, employeeUsername, role string) bool {
+	if err := uuid.Validate(organizationId); err != nil {
+		apiError.GetInvalidRequestFormatOrParametersError(c, err)
+		return false
+	}
+	if role == "" {
+		apiError.GetRoleNotPassedError(c)
+		return false
+	}
+	if !slices.Contains(auth.RolesConst, role) {
+		apiError.GetInvalidRoleError(c)
+		return false
+	}
+	if employeeUsername == "" {
+		apiError.GetEmployeeNotPassedError(c)
+		return false
+	}
+	return true
+}
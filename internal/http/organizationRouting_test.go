@@ -0,0 +1,38 @@
+package http
+
+import (
+	nethttp "net/http"
+	"testing"
+)
+
+func TestOrganizationQuorum(t *testing.T) {
+	s := newTestServer(t)
+	path := "/organizations/" + testOrganizationId + "/quorum"
+
+	var quorum organizationQuorum
+	s.mustDo("alice", nethttp.MethodGet, path, nil, &quorum)
+	if quorum.Quorum != 3 || !quorum.IsDefault {
+		t.Fatalf("кворум %+v, ожидался кворум по умолчанию", quorum)
+	}
+	// Согласовывать может только владелец alice
+	if quorum.EffectiveQuorum != 1 {
+		t.Fatalf("действующий кворум %d, ожидался 1", quorum.EffectiveQuorum)
+	}
+
+	if code := s.do("dave", nethttp.MethodPut, path+"?quorum=1", nil, nil); code != nethttp.StatusForbidden {
+		t.Errorf("владелец другой организации изменил кворум, статус %d", code)
+	}
+	if code := s.do("alice", nethttp.MethodPut, path+"?quorum=0", nil, nil); code != nethttp.StatusBadRequest {
+		t.Errorf("кворум 0 принят, статус %d", code)
+	}
+
+	s.mustDo("alice", nethttp.MethodPut, path+"?quorum=2", nil, &quorum)
+	if quorum.Quorum != 2 || quorum.EffectiveQuorum != 1 {
+		t.Fatalf("кворум %+v, ожидался 2 с действующим 1", quorum)
+	}
+	s.mustDo("alice", nethttp.MethodPost, "/organizations/"+testOrganizationId+"/roles?username=bob&role=approver", nil, nil)
+	s.mustDo("alice", nethttp.MethodGet, path, nil, &quorum)
+	if quorum.Quorum != 2 || quorum.IsDefault || quorum.EffectiveQuorum != 2 {
+		t.Fatalf("кворум %+v, ожидался 2", quorum)
+	}
+}
@@ -0,0 +1,106 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"avitoTask/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const MaxLimit int = 50
+
+// Заголовки ответа списков: общее количество записей без учета пагинации и курсор следующей страницы
+const (
+	TotalCountHeader = "X-Total-Count"
+	NextCursorHeader = "X-Next-Cursor"
+)
+
+var SortFieldsConst []string = []string{repository.SortByName, repository.SortByCreatedAt}
+
+var errInvalidCursor = errors.New("Некорректный курсор или курсор получен для другой сортировки.")
+
+// Курсор: поле и направление сортировки, ключ и идентификатор последней записи предыдущей страницы
+type cursor struct {
+	Sort string `json:"s"`
+	Desc bool   `json:"d"`
+	Key  string `json:"k"`
+	Id   string `json:"i"`
+}
+
+func getPagination(c *gin.Context) (int, int, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if err != nil {
+		return 0, 0, err
+	}
+	if limit < 0 || limit > MaxLimit {
+		return 0, 0, fmt.Errorf("Параметр limit должен быть от 0 до %d.", MaxLimit)
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		return 0, 0, err
+	}
+	if offset < 0 {
+		return 0, 0, errors.New("Параметр offset не может быть отрицательным.")
+	}
+	return limit, offset, nil
+}
+
+// Страница из параметров limit, offset, sort (name или createdAt), order (asc или desc) и cursor.
+// Курсор действителен только для тех же sort и order, offset вместе с курсором не учитывается
+func getPage(c *gin.Context) (repository.Page, error) {
+	limit, offset, err := getPagination(c)
+	if err != nil {
+		return repository.Page{}, err
+	}
+	page := repository.Page{Limit: limit, Offset: offset, Sort: c.DefaultQuery("sort", repository.SortByName)}
+	if !slices.Contains(SortFieldsConst, page.Sort) {
+		return repository.Page{}, fmt.Errorf("Недопустимое поле сортировки %q.", page.Sort)
+	}
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		page.Desc = true
+	default:
+		return repository.Page{}, errors.New("Направление сортировки должно быть asc или desc.")
+	}
+
+	token := c.Query("cursor")
+	if token == "" {
+		return page, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return repository.Page{}, errInvalidCursor
+	}
+	var after cursor
+	err = json.Unmarshal(data, &after)
+	if err != nil || after.Sort != page.Sort || after.Desc != page.Desc || uuid.Validate(after.Id) != nil {
+		return repository.Page{}, errInvalidCursor
+	}
+	if page.Sort == repository.SortByCreatedAt {
+		if _, err := time.Parse(time.RFC3339Nano, after.Key); err != nil {
+			return repository.Page{}, errInvalidCursor
+		}
+	}
+	page.After = &repository.PageKey{Key: after.Key, Id: after.Id}
+	return page, nil
+}
+
+// Записывает в заголовки общее количество записей и, если страница заполнена, курсор следующей
+func setPageHeaders[T repository.Pageable](c *gin.Context, page repository.Page, total int, items []T) {
+	c.Header(TotalCountHeader, strconv.Itoa(total))
+	if page.Limit == 0 || len(items) < page.Limit {
+		return
+	}
+	key := items[len(items)-1].PageKey(page.Sort)
+	data, _ := json.Marshal(cursor{Sort: page.Sort, Desc: page.Desc, Key: key.Key, Id: key.Id})
+	c.Header(NextCursorHeader, base64.RawURLEncoding.EncodeToString(data))
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"encoding/json"
+	nethttp "net/http"
+	"net/url"
+	"testing"
+)
+
+// Страницы по курсору вместе дают тот же список, что и один запрос, без повторов и пропусков
+func TestTendersCursorRoundTrip(t *testing.T) {
+	s := newTestServer(t)
+	for range 3 {
+		s.publishedTender()
+	}
+
+	var all []tenderDto
+	s.mustDo("alice", nethttp.MethodGet, "/tenders/my?sort=createdAt&limit=10", nil, &all)
+	if len(all) != 3 {
+		t.Fatalf("тендеров %d, ожидалось 3", len(all))
+	}
+
+	var paged []tenderDto
+	path := "/tenders/my?sort=createdAt&limit=2"
+	first := s.serve("alice", nethttp.MethodGet, path, nil)
+	if first.Code != nethttp.StatusOK {
+		t.Fatalf("первая страница: статус %d", first.Code)
+	}
+	if total := first.Header().Get(TotalCountHeader); total != "3" {
+		t.Fatalf("%s = %s, ожидалось 3", TotalCountHeader, total)
+	}
+	next := first.Header().Get(NextCursorHeader)
+	if next == "" {
+		t.Fatal("для заполненной страницы не передан курсор")
+	}
+	var page []tenderDto
+	if err := json.Unmarshal(first.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	paged = append(paged, page...)
+
+	second := s.serve("alice", nethttp.MethodGet, path+"&cursor="+url.QueryEscape(next), nil)
+	if second.Code != nethttp.StatusOK {
+		t.Fatalf("вторая страница: статус %d", second.Code)
+	}
+	if cursor := second.Header().Get(NextCursorHeader); cursor != "" {
+		t.Fatalf("для неполной последней страницы передан курсор %s", cursor)
+	}
+	page = nil
+	if err := json.Unmarshal(second.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	paged = append(paged, page...)
+
+	if len(paged) != len(all) {
+		t.Fatalf("по страницам получено %d тендеров, ожидалось %d", len(paged), len(all))
+	}
+	for i := range all {
+		if paged[i].Id != all[i].Id {
+			t.Fatalf("тендер %d: %s, ожидался %s", i, paged[i].Id, all[i].Id)
+		}
+	}
+
+	for _, other := range []string{"/tenders/my?sort=name&limit=2", "/tenders/my?sort=createdAt&order=desc&limit=2"} {
+		code := s.do("alice", nethttp.MethodGet, other+"&cursor="+url.QueryEscape(next), nil, nil)
+		if code != nethttp.StatusBadRequest {
+			t.Errorf("%s с курсором другой сортировки: статус %d, ожидался 400", other, code)
+		}
+	}
+}
+
+// Время создания задает сервер: переданное клиентом не должно влиять на сортировку и фильтры
+func TestCreateTenderIgnoresCreatedAt(t *testing.T) {
+	s := newTestServer(t)
+	var created tenderDto
+	s.mustDo("alice", nethttp.MethodPost, "/tenders/new", map[string]string{
+		"name":           "Тендер",
+		"description":    "Описание",
+		"serviceType":    "Delivery",
+		"organizationId": testOrganizationId,
+		"createdAt":      "2000-01-01T00:00:00Z",
+	}, &created)
+	if created.CreatedAt == "" || created.CreatedAt[:4] == "2000" {
+		t.Fatalf("тендер создан со временем %q", created.CreatedAt)
+	}
+
+	tenderId := s.publishedTender()
+	var bid bidDto
+	s.mustDo("bob", nethttp.MethodPost, "/bids/new", map[string]string{
+		"name":        "Предложение",
+		"description": "Описание",
+		"tenderId":    tenderId,
+		"authorType":  "User",
+		"authorId":    testBobId,
+		"createdAt":   "2000-01-01T00:00:00Z",
+	}, &bid)
+	if bid.CreatedAt == "" || bid.CreatedAt[:4] == "2000" {
+		t.Fatalf("предложение создано со временем %q", bid.CreatedAt)
+	}
+}
@@ -3,23 +3,40 @@ package http
 import (
 	_ "database/sql"
 	"net/http"
+	"time"
+
+	validator "avitoTask/internal"
+	"avitoTask/internal/audit"
+	"avitoTask/internal/auth"
+	"avitoTask/internal/events"
+	"avitoTask/internal/metrics"
+	"avitoTask/internal/outbox"
+	"avitoTask/internal/repository"
+	"avitoTask/internal/service"
+	"avitoTask/internal/webhook"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jmoiron/sqlx"
 )
 
-var db *sqlx.DB
-
-func InitRoutes(conn *sqlx.DB) *gin.Engine {
-	db = conn
+func InitRoutes(store *repository.Store, validator *validator.Validator, auth *auth.Auth, broker *events.Broker,
+	dispatcher *webhook.Dispatcher, relay *outbox.Relay) *gin.Engine {
 	routes := gin.Default()
+	routes.Use(metrics.Middleware())
 
 	routes.GET("/", hello)
-	routeGroup := routes.Group("/api")
+	routes.GET("/metrics", metrics.Handler())
+	routeGroup := routes.Group("/api", audit.RequestId())
 	routeGroup.GET("/ping", ping)
 
-	InitTenderRoutes(routeGroup)
-	InitBidRoutes(routeGroup)
+	authorized := routeGroup.Group("", auth.RequireUser())
+	tenders := service.NewTenderService(store, validator, auth, relay)
+	NewTenderHandler(tenders).InitTenderRoutes(authorized)
+	NewBidHandler(service.NewBidService(store, validator, auth, relay)).InitBidRoutes(authorized)
+	NewOrganizationHandler(service.NewOrganizationService(store, validator, auth)).InitOrganizationRoutes(authorized)
+	NewSearchHandler(service.NewSearchService(store)).InitSearchRoutes(authorized)
+	NewEventHandler(broker, auth, tenders).InitEventRoutes(authorized)
+	NewWebhookHandler(service.NewWebhookService(store, validator, auth, dispatcher)).InitWebhookRoutes(authorized)
+	NewAuditHandler(service.NewAuditService(store, validator, auth)).InitAuditRoutes(authorized)
 
 	return routes
 
@@ -31,3 +48,17 @@ func hello(c *gin.Context) {
 func ping(c *gin.Context) {
 	c.JSON(http.StatusOK, "ok")
 }
+
+// Время в формате RFC3339 из параметра запроса, nil - параметр не передан
+func getTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	t = t.UTC()
+	return &t, nil
+}
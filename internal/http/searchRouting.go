@@ -0,0 +1,111 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"avitoTask/internal/auth"
+	"avitoTask/internal/error"
+	"avitoTask/internal/repository"
+	"avitoTask/internal/service"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// Название и описание, в которых совпадения с запросом выделены <b></b>
+type searchHighlight struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type foundTender struct {
+	*tenderDto
+	Rank      float64         `json:"rank"`
+	Highlight searchHighlight `json:"highlight"`
+}
+
+type foundBid struct {
+	*bidDto
+	Rank      float64         `json:"rank"`
+	Highlight searchHighlight `json:"highlight"`
+}
+
+type searchResult struct {
+	Tenders []foundTender `json:"tenders"`
+	Bids    []foundBid    `json:"bids"`
+}
+
+type SearchHandler struct {
+	search *service.SearchService
+}
+
+var SearchTypesConst []string = []string{service.SearchTypeTender, service.SearchTypeBid}
+
+func NewSearchHandler(search *service.SearchService) *SearchHandler {
+	return &SearchHandler{search: search}
+}
+
+func (h *SearchHandler) InitSearchRoutes(routes *gin.RouterGroup) {
+	//GET
+	routes.GET("/search", h.searchAll)
+}
+
+func newSearchHighlight(m *repository.SearchMatch) searchHighlight {
+	return searchHighlight{Name: m.NameHighlight, Description: m.DescriptionHighlight}
+}
+
+func newSearchResult(r *service.SearchResult) *searchResult {
+	result := &searchResult{
+		Tenders: make([]foundTender, 0, len(r.Tenders)),
+		Bids:    make([]foundBid, 0, len(r.Bids)),
+	}
+	for i := range r.Tenders {
+		result.Tenders = append(result.Tenders, foundTender{
+			tenderDto: newTender(&r.Tenders[i].Tender).convertToDto(),
+			Rank:      r.Tenders[i].Rank,
+			Highlight: newSearchHighlight(&r.Tenders[i].SearchMatch),
+		})
+	}
+	for i := range r.Bids {
+		result.Bids = append(result.Bids, foundBid{
+			bidDto:    newBid(&r.Bids[i].Bid).convertToDto(),
+			Rank:      r.Bids[i].Rank,
+			Highlight: newSearchHighlight(&r.Bids[i].SearchMatch),
+		})
+	}
+	return result
+}
+
+func (h *SearchHandler) searchAll(c *gin.Context) {
+	log.Info("Чтение параметров")
+	query := strings.TrimSpace(c.Query("q"))
+	types := c.QueryArray("type")
+	username := auth.GetUsername(c)
+	limit, offset, err := getPagination(c)
+	if err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
+		return
+	}
+
+	log.Info("Валидация")
+	if query == "" {
+		error.GetSearchQueryNotPassedError(c)
+		return
+	}
+	for _, searchType := range types {
+		if !slices.Contains(SearchTypesConst, searchType) {
+			error.GetInvalidSearchTypeError(c)
+			return
+		}
+	}
+
+	log.Info("Чтение данных")
+	result, err := h.search.Search(username, query, types, limit, offset)
+	if err != nil {
+		getServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newSearchResult(result))
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"errors"
+
+	apiError "avitoTask/internal/error"
+	"avitoTask/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Ответ по ошибке сервиса, неизвестные ошибки считаются внутренними.
+// Пакет ошибок API импортирован под другим именем, чтобы в файле был доступен встроенный тип error
+func getServiceError(c *gin.Context, err error) {
+	var conflictErr *service.StateConflictError
+	switch {
+	case errors.Is(err, service.ErrOrganizationNotFound):
+		apiError.GetOrganizationNotExistsOrIncorrectError(c)
+	case errors.Is(err, service.ErrAuthorNotFound):
+		apiError.GetAuthorNotFoundError(c)
+	case errors.Is(err, service.ErrEmployeeNotFound):
+		apiError.GetEmployeeNotFoundError(c)
+	case errors.Is(err, service.ErrInvalidWebhookUrl):
+		apiError.GetInvalidWebhookUrlError(c)
+	case errors.Is(err, service.ErrWebhookTarget):
+		apiError.GetWebhookTargetNotAllowedError(c)
+	case errors.Is(err, service.ErrInvalidVersion):
+		apiError.GetInvalidVersionError(c)
+	case errors.Is(err, service.ErrVersionBroken):
+		apiError.GetVersionBrokenError(c)
+	case errors.Is(err, service.ErrBidHasDecision):
+		apiError.GetBidAlreadyHasDecisionError(c)
+	case errors.Is(err, service.ErrUserHasDecision):
+		apiError.GetUserHasDecisionForBidError(c)
+	case errors.Is(err, service.ErrUserNotResponsible):
+		apiError.GetUserNotResponsibleOrganizationError(c)
+	case errors.Is(err, service.ErrUserNotAuthor):
+		apiError.GetUserNotAuthorOrResponsibleOrganizationError(c)
+	case errors.Is(err, service.ErrUserCannotViewTender):
+		apiError.GetUserNotViewTenderError(c)
+	case errors.Is(err, service.ErrUserCannotViewBid):
+		apiError.GetUserNotViewBidError(c)
+	case errors.Is(err, service.ErrUserCannotManageRoles):
+		apiError.GetUserCannotManageRolesError(c)
+	case errors.Is(err, service.ErrUserCannotManageQuorum):
+		apiError.GetUserCannotManageQuorumError(c)
+	case errors.Is(err, service.ErrUserCannotManageWebhooks):
+		apiError.GetUserCannotManageWebhooksError(c)
+	case errors.Is(err, service.ErrUserCannotViewAudit):
+		apiError.GetUserCannotViewAuditError(c)
+	case errors.Is(err, service.ErrTenderNotFound):
+		apiError.GetTenderNotFoundError(c)
+	case errors.Is(err, service.ErrBidNotFound):
+		apiError.GetBidNotFoundError(c)
+	case errors.Is(err, service.ErrAuthorBidNotFound):
+		apiError.GetAuthorBidNotFoundError(c)
+	case errors.Is(err, service.ErrVersionNotFound):
+		apiError.GetVersionNotFoundError(c)
+	case errors.Is(err, service.ErrRoleNotFound):
+		apiError.GetRoleNotFoundError(c)
+	case errors.Is(err, service.ErrWebhookNotFound):
+		apiError.GetWebhookNotFoundError(c)
+	case errors.Is(err, service.ErrDeliveryNotFound):
+		apiError.GetWebhookDeliveryNotFoundError(c)
+	case errors.Is(err, service.ErrInvalidTenderStatusTransition):
+		apiError.GetInvalidTenderStatusTransitionError(c)
+	case errors.Is(err, service.ErrInvalidBidStatusTransition):
+		apiError.GetInvalidBidStatusTransitionError(c)
+	case errors.Is(err, service.ErrTenderNotPublished):
+		apiError.GetTenderNotPublishedError(c)
+	case errors.Is(err, service.ErrBidReadOnly):
+		apiError.GetBidReadOnlyError(c)
+	case errors.As(err, &conflictErr):
+		apiError.GetStateConflictError(c, err)
+	default:
+		apiError.GetInternalServerError(c, err)
+	}
+}
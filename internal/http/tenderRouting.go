@@ -1,61 +1,188 @@
 package http
 
 import (
-	"database/sql"
-	"encoding/json"
 	"net/http"
 	"slices"
 	"strconv"
-	"time"
+	"unicode/utf8"
 
-	validator "avitoTask/internal"
+	"avitoTask/internal/audit"
 	"avitoTask/internal/auth"
 	"avitoTask/internal/error"
+	"avitoTask/internal/repository"
+	"avitoTask/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
 )
 
+// Тендер в запросе на создание: status, version и createdAt задает сервер, переданные значения игнорируются
 type tender struct {
-	Id              string `json:"id" db:"id" binding:"max=100"`
-	Name            string `json:"name" db:"name" binding:"required,max=100"`
-	Description     string `json:"description" db:"description" binding:"required,max=500"`
-	ServiceType     string `json:"serviceType" db:"service_type" binding:"required,oneof=Construction Delivery Manufacture"`
-	Status          string `json:"status" db:"status" binding:"required,oneof=Created Published Closed"`
-	Version         int    `json:"version" db:"version" binding:"required,min=1"`
-	OrganizationId  string `json:"organizationId" db:"organization_id" binding:"required,max=100"`
-	CreatedAt       string `json:"createdAt" db:"created_at" binding:"required"`
+	Id              string `json:"id" binding:"max=100"`
+	Name            string `json:"name" binding:"required,max=100"`
+	Description     string `json:"description" binding:"required,max=500"`
+	ServiceType     string `json:"serviceType" binding:"required,oneof=Construction Delivery Manufacture"`
+	Status          string `json:"status"`
+	Version         int    `json:"version"`
+	OrganizationId  string `json:"organizationId" binding:"required,max=100"`
+	CreatedAt       string `json:"createdAt"`
 	CreatorUsername string `json:"creatorUsername"`
 }
 type tenderDto struct {
-	Id          string `json:"id" db:"id" binding:"max=100"`
-	Name        string `json:"name" db:"name" binding:"required,max=100"`
-	Description string `json:"description" db:"description" binding:"required,max=500"`
-	ServiceType string `json:"serviceType" db:"service_type" binding:"required,oneof=Construction Delivery Manufacture"`
-	Status      string `json:"status" db:"status" binding:"required,oneof=Created Published Closed"`
-	Version     int    `json:"version" db:"version" binding:"required,min=1"`
-	CreatedAt   string `json:"createdAt" db:"created_at" binding:"required"`
+	Id          string `json:"id" binding:"max=100"`
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"required,max=500"`
+	ServiceType string `json:"serviceType" binding:"required,oneof=Construction Delivery Manufacture"`
+	Status      string `json:"status" binding:"required,oneof=Created Published Closed"`
+	Version     int    `json:"version" binding:"required,min=1"`
+	CreatedAt   string `json:"createdAt" binding:"required"`
+}
+
+// Версия тендера. changedAt, changedBy, changeKind и comment описывают правку, заменившую версию следующей,
+// у текущей версии не заполняются
+type tenderVersion struct {
+	Version     int    `json:"version"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ServiceType string `json:"serviceType"`
+	Status      string `json:"status"`
+	ChangedAt   string `json:"changedAt,omitempty"`
+	ChangedBy   string `json:"changedBy,omitempty"`
+	ChangeKind  string `json:"changeKind,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+	Broken      bool   `json:"broken,omitempty"`
+}
+
+// Тендер с организацией, количеством видимых пользователю предложений по статусам и итогом выбора предложения
+type tenderDetail struct {
+	*tenderDto
+	Organization tenderOrganization `json:"organization"`
+	BidCounts    map[string]int     `json:"bidCounts"`
+	Decision     tenderDecision     `json:"decision"`
+}
+
+type tenderOrganization struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+}
+
+// outcome: Pending - предложение не выбрано, Approved - принято предложение bidId,
+// Closed - тендер закрыт без принятого предложения
+type tenderDecision struct {
+	Outcome string `json:"outcome"`
+	BidId   string `json:"bidId,omitempty"`
+	BidName string `json:"bidName,omitempty"`
+}
+
+// Изменяемые поля тендера, непереданные поля не меняются
+type tenderEdit struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,min=1,max=500"`
+	ServiceType *string `json:"serviceType" binding:"omitempty,oneof=Construction Delivery Manufacture"`
+}
+
+type TenderHandler struct {
+	tenders *service.TenderService
 }
 
 var StatusConst []string = []string{"Created", "Published", "Closed"}
 var ServiceTypesConst []string = []string{"Construction", "Delivery", "Manufacture"}
 
-func InitTenderRoutes(routes *gin.RouterGroup) {
+const MaxCommentLength int = 500
+
+func NewTenderHandler(tenders *service.TenderService) *TenderHandler {
+	return &TenderHandler{tenders: tenders}
+}
+
+func (h *TenderHandler) InitTenderRoutes(routes *gin.RouterGroup) {
 	tenderRoutes := routes.Group("/tenders")
 	//GET
-	tenderRoutes.GET("/", getTenders)
-	tenderRoutes.GET("/my", getUserTender)
-	tenderRoutes.GET("/:tenderId/status", getStatusTender)
+	tenderRoutes.GET("/", h.getTenders)
+	tenderRoutes.GET("/my", h.getUserTender)
+	tenderRoutes.GET("/:tenderId", h.getTender)
+	tenderRoutes.GET("/:tenderId/status", h.getStatusTender)
+	tenderRoutes.GET("/:tenderId/versions", h.getTenderVersions)
+	tenderRoutes.GET("/:tenderId/versions/:version", h.getTenderVersion)
+	tenderRoutes.GET("/:tenderId/diff", h.getTenderDiff)
 	//POST
-	tenderRoutes.POST("/new", createTender)
+	tenderRoutes.POST("/new", h.createTender)
 	//PUT
-	tenderRoutes.PUT("/:tenderId/status", changeStatusTender)
-	tenderRoutes.PUT("/:tenderId/rollback/:version", rollbackVersionTender)
+	tenderRoutes.PUT("/:tenderId/status", h.changeStatusTender)
+	tenderRoutes.PUT("/:tenderId/rollback/:version", h.rollbackVersionTender)
 	//PATCH
-	tenderRoutes.PATCH("/:tenderId/edit", editTender)
+	tenderRoutes.PATCH("/:tenderId/edit", h.editTender)
+
+}
+
+func newTender(t *repository.Tender) *tender {
+	return &tender{
+		Id:             t.Id,
+		Name:           t.Name,
+		Description:    t.Description,
+		ServiceType:    t.ServiceType,
+		Status:         t.Status,
+		Version:        t.Version,
+		OrganizationId: t.OrganizationId,
+		CreatedAt:      t.CreatedAt,
+	}
+}
+
+func newTenderDetail(d *service.TenderDetail) *tenderDetail {
+	detail := &tenderDetail{
+		tenderDto: newTender(d.Tender).convertToDto(),
+		Organization: tenderOrganization{
+			Id:          d.Organization.Id,
+			Name:        d.Organization.Name,
+			Description: d.Organization.Description,
+			Type:        d.Organization.Type,
+		},
+		BidCounts: d.BidCounts,
+		Decision:  tenderDecision{Outcome: d.Outcome},
+	}
+	if d.ApprovedBid != nil {
+		detail.Decision.BidId = d.ApprovedBid.Id
+		detail.Decision.BidName = d.ApprovedBid.Name
+	}
+	return detail
+}
 
+func newTenderVersion(t *repository.TenderVersion) *tenderVersion {
+	return &tenderVersion{
+		Version:     t.Version,
+		Name:        t.Name,
+		Description: t.Description,
+		ServiceType: t.ServiceType,
+		Status:      t.Status,
+		ChangedAt:   t.ChangedAt,
+		ChangedBy:   t.ChangedBy,
+		ChangeKind:  t.ChangeKind,
+		Comment:     t.Comment,
+		Broken:      t.Broken,
+	}
+}
+
+func (t *tenderEdit) toEdit() service.TenderEdit {
+	return service.TenderEdit{
+		Name:        t.Name,
+		Description: t.Description,
+		ServiceType: t.ServiceType,
+	}
+}
+
+func (t *tender) toModel() *repository.Tender {
+	return &repository.Tender{
+		Id:             t.Id,
+		Name:           t.Name,
+		Description:    t.Description,
+		ServiceType:    t.ServiceType,
+		Status:         t.Status,
+		Version:        t.Version,
+		OrganizationId: t.OrganizationId,
+		CreatedAt:      t.CreatedAt,
+	}
 }
 
 func (t *tender) convertToDto() *tenderDto {
@@ -70,239 +197,167 @@ func (t *tender) convertToDto() *tenderDto {
 	return &tenderDto
 }
 
-func getTenders(c *gin.Context) {
+func convertTendersToDto(tenders []repository.Tender) []tenderDto {
+	tenderDtos := make([]tenderDto, 0, len(tenders))
+	for i := range tenders {
+		tenderDtos = append(tenderDtos, *newTender(&tenders[i]).convertToDto())
+	}
+	return tenderDtos
+}
+
+func (h *TenderHandler) getTenders(c *gin.Context) {
 	log.Info("Чтение параметров")
-	limit := c.Query("limit")
-	offset := c.Query("offset")
+	page, err := getPage(c)
+	if err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
+		return
+	}
 
-	log.Info("Валидация")
-	if limit == "" {
-		limit = "5"
+	filter := repository.TenderFilter{
+		ServiceTypes:   c.QueryArray("service_type"),
+		Statuses:       c.QueryArray("status"),
+		OrganizationId: c.Query("organizationId"),
+		Name:           c.Query("name"),
 	}
-	if offset == "" {
-		offset = "0"
+	filter.CreatedFrom, err = getTimeQuery(c, "createdFrom")
+	if err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
+		return
 	}
-	serviceTypes := c.QueryArray("service_type")
-	for _, serviceType := range serviceTypes {
+	filter.CreatedTo, err = getTimeQuery(c, "createdTo")
+	if err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
+		return
+	}
+	username := auth.GetUsername(c)
+
+	log.Info("Валидация")
+	for _, serviceType := range filter.ServiceTypes {
 		if !slices.Contains(ServiceTypesConst, serviceType) {
 			error.GetInvalidServiceTypeError(c)
 			return
 		}
 
 	}
+	for _, status := range filter.Statuses {
+		if !slices.Contains(StatusConst, status) {
+			error.GetInvalidStatusError(c)
+			return
+		}
+	}
+	if filter.OrganizationId != "" {
+		if err := uuid.Validate(filter.OrganizationId); err != nil {
+			error.GetInvalidRequestFormatOrParametersError(c, err)
+			return
+		}
+	}
 
 	log.Info("Чтение данных")
-	query := `
-		SELECT id,
-	       name,
-		   COALESCE(description,'') as description,
-	       status,
-	       service_type,
-	       version,
-	       created_at
-		FROM   tender
-		WHERE  service_type = ANY ( $1 )
-				OR $2 = 0
-		ORDER BY name
-		LIMIT $3 OFFSET $4`
-
-	var tenders []tenderDto
-	err := db.Select(&tenders, query, pq.Array(serviceTypes), len(serviceTypes), limit, offset)
+	tenders, total, err := h.tenders.List(username, filter, page)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, tenders)
+	setPageHeaders(c, page, total, tenders)
+	c.JSON(http.StatusOK, convertTendersToDto(tenders))
 }
 
-func getUserTender(c *gin.Context) {
+func (h *TenderHandler) getUserTender(c *gin.Context) {
 	log.Info("Чтение параметров")
-	limit := c.Query("limit")
-	offset := c.Query("offset")
-	username := c.Query("username")
-	log.Info("Валидация")
-	if limit == "" {
-		limit = "5"
-	}
-	if offset == "" {
-		offset = "0"
-	}
-	if username == "" {
-		error.GetUserNotPassedError(c)
-		return
-	}
-	err := validator.CheckUserExists(username)
-	if err == sql.ErrNoRows {
-		error.GetUserNotExistsOrIncorrectError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+	page, err := getPage(c)
+	if err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
+	username := auth.GetUsername(c)
 
 	log.Info("Чтение")
-	query := `SELECT t.id,
-					t.name,
-					COALESCE(t.description, '') AS description,
-					t.status,
-					t.service_type,
-					t.version,
-					t.created_at
-				FROM tender t
-					JOIN organization_responsible org_r ON org_r.organization_id = t.organization_id
-					JOIN employee e ON org_r.user_id = e.id
-				WHERE e.username = $1
-				ORDER BY name
-						LIMIT $2 OFFSET $3`
-	var tenders []tenderDto
-	err = db.Select(&tenders, query, username, limit, offset)
+	tenders, total, err := h.tenders.ListByMember(username, page)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, tenders)
+	setPageHeaders(c, page, total, tenders)
+	c.JSON(http.StatusOK, convertTendersToDto(tenders))
 }
 
-func getStatusTender(c *gin.Context) {
+func (h *TenderHandler) getTender(c *gin.Context) {
 	log.Info("Чтение параметров")
 	tenderId := c.Param("tenderId")
-	username := c.Query("username")
+	username := auth.GetUsername(c)
 
 	log.Info("Валидация")
-	if tenderId == "" {
-		error.GetTenderIdNotPassedError(c)
-		return
-	}
 	if err := uuid.Validate(tenderId); err != nil {
 		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	if username == "" {
-		error.GetUserNotPassedError(c)
-		return
-	}
 
-	err := validator.CheckUserExists(username)
-	if err == sql.ErrNoRows {
-		error.GetUserNotExistsOrIncorrectError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+	log.Info("Чтение данных")
+	detail, err := h.tenders.GetDetail(username, tenderId)
+	if err != nil {
+		getServiceError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, newTenderDetail(detail))
+}
 
-	err = validator.CheckTenderExists(tenderId)
-	if err == sql.ErrNoRows {
-		error.GetTenderNotFoundError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
+func (h *TenderHandler) getStatusTender(c *gin.Context) {
+	log.Info("Чтение параметров")
+	tenderId := c.Param("tenderId")
+	username := auth.GetUsername(c)
 
-	log.Info("Авторизация")
-	err = auth.CheckUserViewTender(username, tenderId)
-	if err == sql.ErrNoRows {
-		error.GetUserNotViewTenderError(c)
+	log.Info("Валидация")
+	if tenderId == "" {
+		error.GetTenderIdNotPassedError(c)
 		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+	}
+	if err := uuid.Validate(tenderId); err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
 
 	log.Info("Чтение данных")
-	var status string
-	err = db.Get(&status, "SELECT status FROM tender WHERE id = $1", tenderId)
+	status, err := h.tenders.GetStatus(username, tenderId)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, status)
 }
 
-func createTender(c *gin.Context) {
+func (h *TenderHandler) createTender(c *gin.Context) {
 	log.Info("Чтение параметров")
-	someTender := tender{Version: 1, CreatedAt: time.Now().Format(time.RFC3339), Status: "Created"}
+	var someTender tender
 	err := c.BindJSON(&someTender)
 	if err != nil {
 		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
+	someTender.CreatorUsername = auth.GetUsername(c)
 	log.Info("Валидация")
 	if err := uuid.Validate(someTender.OrganizationId); err != nil {
 		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
 
-	err = validator.CheckUserExists(someTender.CreatorUsername)
-	if err == sql.ErrNoRows {
-		error.GetUserNotExistsOrIncorrectError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
-
-	err = validator.CheckOrganizationExists(someTender.OrganizationId)
-	if err == sql.ErrNoRows {
-		error.GetOrganizationNotExistsOrIncorrectError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
-
-	log.Info("Авторизация")
-	err = auth.CheckUserCanManageTender(someTender.CreatorUsername, someTender.OrganizationId)
-	if err == sql.ErrNoRows {
-		error.GetUserNotResponsibleOrganizationError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
-
 	log.Info("Создание")
-	var lastInsertId string
-	tx, err := db.Beginx()
+	model := someTender.toModel()
+	err = h.tenders.Create(audit.GetActor(c), model)
 	if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
-	defer tx.Rollback()
-	err = tx.QueryRow(`INSERT INTO tender
-									(name,
-									description,
-									service_type,
-									status,
-									organization_id,
-									version,
-									created_at)
-						VALUES     ($1,
-									$2,
-									$3,
-									$4,
-									$5,
-									$6,
-									$7)
-						RETURNING id`, someTender.Name, someTender.Description, someTender.ServiceType, someTender.Status, someTender.OrganizationId,
-		someTender.Version, someTender.CreatedAt).Scan(&lastInsertId)
-	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
-	tx.Commit()
-	someTender.Id = lastInsertId
 
-	c.JSON(http.StatusOK, someTender.convertToDto())
+	c.JSON(http.StatusOK, newTender(model).convertToDto())
 }
 
-func changeStatusTender(c *gin.Context) {
+func (h *TenderHandler) changeStatusTender(c *gin.Context) {
 	log.Info("Чтение параметров")
 	status := c.Query("status")
-	username := c.Query("username")
+	comment := c.Query("comment")
+	actor := audit.GetActor(c)
 	tenderId := c.Param("tenderId")
 
 	log.Info("Валидация")
@@ -314,6 +369,10 @@ func changeStatusTender(c *gin.Context) {
 		error.GetInvalidStatusError(c)
 		return
 	}
+	if utf8.RuneCountInString(comment) > MaxCommentLength {
+		error.GetInvalidCommentError(c)
+		return
+	}
 
 	if tenderId == "" {
 		error.GetTenderIdNotPassedError(c)
@@ -323,91 +382,58 @@ func changeStatusTender(c *gin.Context) {
 		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	err := validator.CheckTenderExists(tenderId)
-	if err == sql.ErrNoRows {
-		error.GetTenderNotFoundError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
-
-	if username == "" {
-		error.GetUserNotPassedError(c)
-		return
-	}
-	err = validator.CheckUserExists(username)
-	if err == sql.ErrNoRows {
-		error.GetUserNotExistsOrIncorrectError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
 
-	log.Info("Чтение данных")
-	var tender tender
-	err = db.Get(&tender, `SELECT id,
-								name,
-								COALESCE(description,'') as description,
-								status,
-								service_type,
-								organization_id,
-								version,
-								created_at
-							FROM tender WHERE id = $1`, tenderId)
+	log.Info("Изменение")
+	tender, err := h.tenders.ChangeStatus(actor, tenderId, status, comment)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
 
-	log.Info("Авторизация")
-	err = auth.CheckUserCanManageTender(username, tender.OrganizationId)
-	if err == sql.ErrNoRows {
-		error.GetUserNotResponsibleOrganizationError(c)
+	c.JSON(http.StatusOK, newTender(tender).convertToDto())
+}
+
+func (h *TenderHandler) editTender(c *gin.Context) {
+	log.Info("Чтение параметров")
+	tenderId := c.Param("tenderId")
+	comment := c.Query("comment")
+	actor := audit.GetActor(c)
+
+	log.Info("Валидация")
+	if tenderId == "" {
+		error.GetTenderIdNotPassedError(c)
 		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+	}
+	if err := uuid.Validate(tenderId); err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-
-	log.Info("Изменение")
-	tx, err := db.Beginx()
-	if err != nil {
-		error.GetInternalServerError(c, err)
+	if utf8.RuneCountInString(comment) > MaxCommentLength {
+		error.GetInvalidCommentError(c)
 		return
 	}
-	defer tx.Rollback()
-	_, err = tx.Exec("UPDATE tender SET status = $1 WHERE id = $2", status, tender.Id)
+
+	var edit tenderEdit
+	err := c.BindJSON(&edit)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	tx.Commit()
 
-	log.Info("Чтение данных")
-	err = db.Get(&tender, `SELECT id,
-								name,
-								COALESCE(description,'') as description,
-								status,
-								service_type,
-								organization_id,
-								version,
-								created_at
-							FROM tender
-							WHERE id = $1`, tender.Id)
+	log.Info("Изменение")
+	tender, err := h.tenders.Edit(actor, tenderId, edit.toEdit(), comment)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, tender.convertToDto())
+	c.JSON(http.StatusOK, newTender(tender).convertToDto())
 }
-
-func editTender(c *gin.Context) {
+func (h *TenderHandler) rollbackVersionTender(c *gin.Context) {
 	log.Info("Чтение параметров")
 	tenderId := c.Param("tenderId")
-	username := c.Query("username")
+	comment := c.Query("comment")
+	actor := audit.GetActor(c)
 
 	log.Info("Валидация")
 	if tenderId == "" {
@@ -418,107 +444,60 @@ func editTender(c *gin.Context) {
 		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	err := validator.CheckTenderExists(tenderId)
-	if err == sql.ErrNoRows {
-		error.GetTenderNotFoundError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
-
-	if username == "" {
-		error.GetUserNotPassedError(c)
-		return
-	}
-	err = validator.CheckUserExists(username)
-	if err == sql.ErrNoRows {
-		error.GetUserNotExistsOrIncorrectError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
-
-	log.Info("Чтение данных")
-	var tender tender
-	err = db.Get(&tender, `SELECT id,
-								name,
-								COALESCE(description,'') as description,
-								status,
-								service_type,
-								organization_id,
-								version,
-								created_at
-							FROM tender WHERE id = $1`, tenderId)
-	if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
 
-	err = c.BindJSON(&tender)
+	version, err := strconv.Atoi(c.Param("version"))
 	if err != nil {
 		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	if !slices.Contains(ServiceTypesConst, tender.ServiceType) {
-		error.GetInvalidServiceTypeError(c)
+	if utf8.RuneCountInString(comment) > MaxCommentLength {
+		error.GetInvalidCommentError(c)
 		return
 	}
 
-	log.Info("Авторизация")
-	err = auth.CheckUserCanManageTender(username, tender.OrganizationId)
-	if err == sql.ErrNoRows {
-		error.GetUserNotResponsibleOrganizationError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+	log.Info("Изменение")
+	tender, err := h.tenders.Rollback(actor, tenderId, version, comment)
+	if err != nil {
+		getServiceError(c, err)
 		return
 	}
 
-	log.Info("Изменение")
-	query := `UPDATE tender
-				SET    name = :name,
-						description = :description,
-						service_type = :service_type
-				WHERE  id = :id`
+	c.JSON(http.StatusOK, newTender(tender).convertToDto())
+}
 
-	tx, err := db.Beginx()
-	if err != nil {
-		error.GetInternalServerError(c, err)
+func (h *TenderHandler) getTenderVersions(c *gin.Context) {
+	log.Info("Чтение параметров")
+	tenderId := c.Param("tenderId")
+	username := auth.GetUsername(c)
+
+	log.Info("Валидация")
+	if tenderId == "" {
+		error.GetTenderIdNotPassedError(c)
 		return
 	}
-	defer tx.Rollback()
-
-	_, err = tx.NamedExec(query, tender)
-	if err != nil {
-		error.GetInternalServerError(c, err)
+	if err := uuid.Validate(tenderId); err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	tx.Commit()
 
 	log.Info("Чтение данных")
-	err = db.Get(&tender, `SELECT id,
-								name,
-								COALESCE(description,'') as description,
-								status,
-								service_type,
-								organization_id,
-								version,
-								created_at
-							FROM tender
-							WHERE id = $1`, tenderId)
+	versions, err := h.tenders.ListVersions(username, tenderId)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, tender.convertToDto())
+	tenderVersions := make([]tenderVersion, 0, len(versions))
+	for i := range versions {
+		tenderVersions = append(tenderVersions, *newTenderVersion(&versions[i]))
+	}
+	c.JSON(http.StatusOK, tenderVersions)
 }
-func rollbackVersionTender(c *gin.Context) {
+
+func (h *TenderHandler) getTenderVersion(c *gin.Context) {
 	log.Info("Чтение параметров")
 	tenderId := c.Param("tenderId")
-	username := c.Query("username")
+	username := auth.GetUsername(c)
 
 	log.Info("Валидация")
 	if tenderId == "" {
@@ -529,14 +508,6 @@ func rollbackVersionTender(c *gin.Context) {
 		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	err := validator.CheckTenderExists(tenderId)
-	if err == sql.ErrNoRows {
-		error.GetTenderNotFoundError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
 
 	version, err := strconv.Atoi(c.Param("version"))
 	if err != nil {
@@ -544,91 +515,47 @@ func rollbackVersionTender(c *gin.Context) {
 		return
 	}
 
-	if username == "" {
-		error.GetUserNotPassedError(c)
-		return
-	}
-	err = validator.CheckUserExists(username)
-	if err == sql.ErrNoRows {
-		error.GetUserNotExistsOrIncorrectError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
-		return
-	}
-
 	log.Info("Чтение данных")
-	var tender tender
-	err = db.Get(&tender, `SELECT id,
-								name,
-								COALESCE(description,'') as description,
-								status,
-								service_type,
-								organization_id,
-								version,
-								created_at
-							FROM tender WHERE id = $1`, tenderId)
+	tenderVersion, err := h.tenders.GetVersion(username, tenderId, version)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
 
-	log.Info("Авторизация")
-	err = auth.CheckUserCanManageTender(username, tender.OrganizationId)
-	if err == sql.ErrNoRows {
-		error.GetUserNotResponsibleOrganizationError(c)
-		return
-	} else if err != nil {
-		error.GetInternalServerError(c, err)
+	c.JSON(http.StatusOK, newTenderVersion(tenderVersion))
+}
+
+func (h *TenderHandler) getTenderDiff(c *gin.Context) {
+	log.Info("Чтение параметров")
+	tenderId := c.Param("tenderId")
+	username := auth.GetUsername(c)
+
+	log.Info("Валидация")
+	if tenderId == "" {
+		error.GetTenderIdNotPassedError(c)
 		return
 	}
-
-	if version >= tender.Version {
-		error.GetInvalidVersionError(c)
+	if err := uuid.Validate(tenderId); err != nil {
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
 
-	log.Info("Чтение данных")
-	var params string
-	err = db.Get(&params, `SELECT params 
-							FROM tender_version_hist 
-							WHERE tender_id = $1 AND version = $2`, tender.Id, version)
-	if err != nil {
-		error.GetVersionNotFoundError(c)
+	if c.Query("from") == "" {
+		error.GetVersionNotPassedError(c)
 		return
 	}
-	json.Unmarshal([]byte(params), &tender)
-
-	log.Info("Изменение")
-	query := `UPDATE tender
-				SET    name = :name,
-						description = :description,
-						service_type = :service_type
-				WHERE  id = :id`
-
-	tx := db.MustBegin()
-	_, err = tx.NamedExec(query, &tender)
+	from, to, unified, err := getDiffParams(c)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		error.GetInvalidRequestFormatOrParametersError(c, err)
 		return
 	}
-	tx.Commit()
 
 	log.Info("Чтение данных")
-	err = db.Get(&tender, `SELECT id,
-								name,
-								COALESCE(description,'') as description,
-								status,
-								service_type,
-								organization_id,
-								version,
-								created_at
-							FROM tender
-							WHERE id = $1`, tenderId)
+	diff, err := h.tenders.Diff(username, tenderId, from, to)
 	if err != nil {
-		error.GetInternalServerError(c, err)
+		getServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, tender.convertToDto())
+	c.JSON(http.StatusOK, newVersionDiff(diff, unified))
 }
@@ -0,0 +1,45 @@
+package http
+
+import (
+	nethttp "net/http"
+	"testing"
+)
+
+func TestTenderDetailCountsOnlyVisibleBids(t *testing.T) {
+	s := newTestServer(t)
+	tenderId := s.publishedTender()
+	s.draftBid("bob", testBobId, tenderId)
+
+	for username, created := range map[string]int{"bob": 1, "carol": 0, "alice": 0} {
+		var detail struct {
+			BidCounts map[string]int `json:"bidCounts"`
+		}
+		s.mustDo(username, nethttp.MethodGet, "/tenders/"+tenderId, nil, &detail)
+		if detail.BidCounts["Created"] != created {
+			t.Errorf("%s видит %d черновиков, ожидалось %d", username, detail.BidCounts["Created"], created)
+		}
+	}
+}
+
+// Статус и версию нового тендера задает сервер, иначе тендер можно было бы создать в обход переходов статусов
+func TestCreateTenderIgnoresStatusAndVersion(t *testing.T) {
+	s := newTestServer(t)
+	var created tenderDto
+	s.mustDo("alice", nethttp.MethodPost, "/tenders/new", map[string]any{
+		"name":           "Тендер",
+		"description":    "Описание",
+		"serviceType":    "Delivery",
+		"organizationId": testOrganizationId,
+		"status":         "Closed",
+		"version":        7,
+	}, &created)
+	if created.Status != "Created" || created.Version != 1 {
+		t.Fatalf("тендер создан со статусом %s и версией %d, ожидались Created и 1", created.Status, created.Version)
+	}
+
+	var status string
+	s.mustDo("alice", nethttp.MethodGet, "/tenders/"+created.Id+"/status", nil, &status)
+	if status != "Created" {
+		t.Fatalf("сохранен статус %s, ожидался Created", status)
+	}
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"strconv"
+
+	"avitoTask/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type versionDiff struct {
+	From            int           `json:"from"`
+	To              int           `json:"to"`
+	Changes         []fieldChange `json:"changes"`
+	DescriptionDiff string        `json:"descriptionDiff,omitempty"`
+}
+
+// Текстовое сравнение описаний возвращается только по запросу unified=true
+func newVersionDiff(d *service.VersionDiff, unified bool) *versionDiff {
+	diff := versionDiff{From: d.From, To: d.To, Changes: make([]fieldChange, 0, len(d.Changes))}
+	for _, change := range d.Changes {
+		diff.Changes = append(diff.Changes, fieldChange{Field: change.Field, From: change.From, To: change.To})
+	}
+	if unified {
+		diff.DescriptionDiff = d.DescriptionDiff
+	}
+	return &diff
+}
+
+// Версии для сравнения: from обязательна, без to сравнение идет с текущей версией
+func getDiffParams(c *gin.Context) (int, int, bool, error) {
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		return 0, 0, false, err
+	}
+	to, err := strconv.Atoi(c.DefaultQuery("to", "0"))
+	if err != nil {
+		return 0, 0, false, err
+	}
+	unified, err := strconv.ParseBool(c.DefaultQuery("unified", "false"))
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return from, to, unified, nil
+}
@@ -0,0 +1,254 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"avitoTask/internal/audit"
+	"avitoTask/internal/auth"
+	apiError "avitoTask/internal/error"
+	"avitoTask/internal/events"
+	"avitoTask/internal/repository"
+	"avitoTask/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// Подписка на события. Секрет для проверки подписи возвращается только при создании
+type webhookDto struct {
+	Id         string   `json:"id"`
+	Url        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	Secret     string   `json:"secret,omitempty"`
+	CreatedBy  string   `json:"createdBy"`
+	CreatedAt  string   `json:"createdAt"`
+}
+
+// Новая подписка, пустой eventTypes - подписка на все события
+type newWebhook struct {
+	Url        string   `json:"url" binding:"required,max=500"`
+	EventTypes []string `json:"eventTypes"`
+}
+
+// Доставка из журнала, nextAttemptAt - время следующей попытки доставки в статусе Pending
+type webhookDelivery struct {
+	Id            string          `json:"id"`
+	EventType     string          `json:"eventType"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	ResponseCode  int             `json:"responseCode,omitempty"`
+	LastError     string          `json:"lastError,omitempty"`
+	NextAttemptAt string          `json:"nextAttemptAt,omitempty"`
+	CreatedAt     string          `json:"createdAt"`
+	UpdatedAt     string          `json:"updatedAt"`
+}
+
+type WebhookHandler struct {
+	webhooks *service.WebhookService
+}
+
+func NewWebhookHandler(webhooks *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhooks: webhooks}
+}
+
+func (h *WebhookHandler) InitWebhookRoutes(routes *gin.RouterGroup) {
+	webhookRoutes := routes.Group("/organizations/:organizationId/webhooks")
+	//GET
+	webhookRoutes.GET("", h.getWebhooks)
+	webhookRoutes.GET("/:webhookId/deliveries", h.getWebhookDeliveries)
+	//POST
+	webhookRoutes.POST("", h.createWebhook)
+	webhookRoutes.POST("/:webhookId/deliveries/:deliveryId/redeliver", h.redeliverWebhook)
+	//DELETE
+	webhookRoutes.DELETE("/:webhookId", h.deleteWebhook)
+}
+
+func newWebhookDto(w *repository.Webhook) *webhookDto {
+	eventTypes := w.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	return &webhookDto{
+		Id:         w.Id,
+		Url:        w.Url,
+		EventTypes: eventTypes,
+		CreatedBy:  w.CreatedBy,
+		CreatedAt:  w.CreatedAt,
+	}
+}
+
+func newWebhookDelivery(d *repository.WebhookDelivery) *webhookDelivery {
+	delivery := &webhookDelivery{
+		Id:           d.Id,
+		EventType:    d.EventType,
+		Payload:      json.RawMessage(d.Payload),
+		Status:       d.Status,
+		Attempts:     d.Attempts,
+		ResponseCode: d.ResponseCode,
+		LastError:    d.LastError,
+		CreatedAt:    d.CreatedAt,
+		UpdatedAt:    d.UpdatedAt,
+	}
+	if d.Status == repository.WebhookDeliveryPending {
+		delivery.NextAttemptAt = d.NextAttemptAt
+	}
+	return delivery
+}
+
+// Проверяет формат идентификаторов организации и подписки
+func validateWebhookIds(c *gin.Context, organizationId, webhookId string) bool {
+	if err := uuid.Validate(organizationId); err != nil {
+		apiError.GetInvalidRequestFormatOrParametersError(c, err)
+		return false
+	}
+	if err := uuid.Validate(webhookId); err != nil {
+		apiError.GetInvalidRequestFormatOrParametersError(c, err)
+		return false
+	}
+	return true
+}
+
+func (h *WebhookHandler) getWebhooks(c *gin.Context) {
+	log.Info("Чтение параметров")
+	organizationId := c.Param("organizationId")
+	username := auth.GetUsername(c)
+
+	log.Info("Валидация")
+	if err := uuid.Validate(organizationId); err != nil {
+		apiError.GetInvalidRequestFormatOrParametersError(c, err)
+		return
+	}
+
+	log.Info("Чтение")
+	webhooks, err := h.webhooks.List(username, organizationId)
+	if err != nil {
+		getServiceError(c, err)
+		return
+	}
+
+	webhookDtos := make([]webhookDto, 0, len(webhooks))
+	for i := range webhooks {
+		webhookDtos = append(webhookDtos, *newWebhookDto(&webhooks[i]))
+	}
+	c.JSON(http.StatusOK, webhookDtos)
+}
+
+func (h *WebhookHandler) createWebhook(c *gin.Context) {
+	log.Info("Чтение параметров")
+	organizationId := c.Param("organizationId")
+	actor := audit.GetActor(c)
+	var someWebhook newWebhook
+	if err := c.BindJSON(&someWebhook); err != nil {
+		apiError.GetInvalidRequestFormatOrParametersError(c, err)
+		return
+	}
+
+	log.Info("Валидация")
+	if err := uuid.Validate(organizationId); err != nil {
+		apiError.GetInvalidRequestFormatOrParametersError(c, err)
+		return
+	}
+	for _, eventType := range someWebhook.EventTypes {
+		if !slices.Contains(events.TypesConst, eventType) {
+			apiError.GetInvalidEventTypeError(c)
+			return
+		}
+	}
+
+	log.Info("Создание")
+	model := &repository.Webhook{
+		OrganizationId: organizationId,
+		Url:            someWebhook.Url,
+		EventTypes:     someWebhook.EventTypes,
+	}
+	err := h.webhooks.Create(c.Request.Context(), actor, model)
+	if err != nil {
+		getServiceError(c, err)
+		return
+	}
+
+	created := newWebhookDto(model)
+	created.Secret = model.Secret
+	c.JSON(http.StatusOK, created)
+}
+
+func (h *WebhookHandler) deleteWebhook(c *gin.Context) {
+	log.Info("Чтение параметров")
+	organizationId := c.Param("organizationId")
+	webhookId := c.Param("webhookId")
+	actor := audit.GetActor(c)
+
+	log.Info("Валидация")
+	if !validateWebhookIds(c, organizationId, webhookId) {
+		return
+	}
+
+	log.Info("Удаление")
+	deleted, err := h.webhooks.Delete(actor, organizationId, webhookId)
+	if err != nil {
+		getServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newWebhookDto(deleted))
+}
+
+func (h *WebhookHandler) getWebhookDeliveries(c *gin.Context) {
+	log.Info("Чтение параметров")
+	organizationId := c.Param("organizationId")
+	webhookId := c.Param("webhookId")
+	username := auth.GetUsername(c)
+	limit, offset, err := getPagination(c)
+	if err != nil {
+		apiError.GetInvalidRequestFormatOrParametersError(c, err)
+		return
+	}
+
+	log.Info("Валидация")
+	if !validateWebhookIds(c, organizationId, webhookId) {
+		return
+	}
+
+	log.Info("Чтение")
+	deliveries, err := h.webhooks.ListDeliveries(username, organizationId, webhookId, limit, offset)
+	if err != nil {
+		getServiceError(c, err)
+		return
+	}
+
+	webhookDeliveries := make([]webhookDelivery, 0, len(deliveries))
+	for i := range deliveries {
+		webhookDeliveries = append(webhookDeliveries, *newWebhookDelivery(&deliveries[i]))
+	}
+	c.JSON(http.StatusOK, webhookDeliveries)
+}
+
+func (h *WebhookHandler) redeliverWebhook(c *gin.Context) {
+	log.Info("Чтение параметров")
+	organizationId := c.Param("organizationId")
+	webhookId := c.Param("webhookId")
+	deliveryId := c.Param("deliveryId")
+	actor := audit.GetActor(c)
+
+	log.Info("Валидация")
+	if !validateWebhookIds(c, organizationId, webhookId) {
+		return
+	}
+	if err := uuid.Validate(deliveryId); err != nil {
+		apiError.GetInvalidRequestFormatOrParametersError(c, err)
+		return
+	}
+
+	log.Info("Повторная доставка")
+	delivery, err := h.webhooks.Redeliver(actor, organizationId, webhookId, deliveryId)
+	if err != nil {
+		getServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newWebhookDelivery(delivery))
+}
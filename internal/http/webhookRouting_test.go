@@ -0,0 +1,21 @@
+package http
+
+import (
+	nethttp "net/http"
+	"testing"
+)
+
+// Права проверяются до разрешения имени хоста подписки: посторонний не может заставить сервис
+// обращаться к DNS и по ответу узнавать, разрешается ли имя
+func TestCreateWebhookAuthorizesBeforeLookup(t *testing.T) {
+	s := newTestServer(t)
+	path := "/organizations/" + testOrganizationId + "/webhooks"
+	body := map[string]any{"url": "http://hook.invalid/events"}
+
+	if code := s.do("dave", nethttp.MethodPost, path, body, nil); code != nethttp.StatusForbidden {
+		t.Errorf("владелец другой организации получил статус %d, ожидался 403", code)
+	}
+	if code := s.do("alice", nethttp.MethodPost, path, body, nil); code != nethttp.StatusBadRequest {
+		t.Errorf("неразрешимый адрес принят, статус %d", code)
+	}
+}
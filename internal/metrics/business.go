@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	TendersCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tenders_created_total",
+		Help: "Количество созданных тендеров.",
+	})
+	// Предложение считается поданным при публикации, черновики не учитываются
+	BidsSubmitted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "bids_submitted_total",
+		Help: "Количество опубликованных предложений.",
+	})
+	BidDecisions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bid_decisions_total",
+		Help: "Количество решений согласующих по предложениям.",
+	}, []string{"decision"})
+	TendersClosedByQuorum = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tenders_closed_by_quorum_total",
+		Help: "Количество тендеров, закрытых по кворуму согласований.",
+	})
+)
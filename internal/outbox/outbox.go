@@ -0,0 +1,199 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"avitoTask/internal/events"
+	"avitoTask/internal/repository"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Количество событий, передаваемых получателю в одной транзакции
+const relayBatchSize = 100
+
+// Получатель событий из outbox
+type Sink interface {
+	// Имя получателя, по нему отмечаются обработанные события
+	Name() string
+	// Вызывается в транзакции, отмечающей событие обработанным. Возвращаемая функция (может быть nil)
+	// вызывается после фиксации транзакции, в ней выполняются действия, которые нельзя отменить
+	Handle(tx *repository.Store, event repository.OutboxEvent) (func(), error)
+}
+
+// Получатель, которому нужен ввод-вывод до отметки событий обработанными. Prepare вызывается вне
+// транзакции, чтобы не держать ее во время записи, и должен быть идемпотентным: если транзакция
+// не зафиксирована, события передаются повторно. Handle таких получателей не выполняет ввод-вывод
+type Preparer interface {
+	Prepare(events []repository.OutboxEvent) error
+}
+
+// Возвращается из Handle для события, которое поступило в outbox после Prepare. Это и следующие
+// события остаются необработанными до следующего опроса
+var ErrNotPrepared error = errors.New("событие не подготовлено получателем")
+
+// Сообщает о новых событиях в outbox после фиксации транзакции
+type Notifier interface {
+	Notify()
+}
+
+// Записывает событие в outbox, вызывается в транзакции изменения
+func Add(tx *repository.Store, event events.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return tx.Outbox.Create(&repository.OutboxEvent{
+		EventType: event.Type,
+		TenderId:  event.TenderId,
+		Payload:   string(payload),
+	})
+}
+
+// Как часто удаляются старые обработанные события
+const cleanupInterval = time.Hour
+
+// Передает события из outbox получателям. Опрашивает outbox с интервалом interval
+// и сразу после Notify
+type Relay struct {
+	store     *repository.Store
+	sinks     []Sink
+	interval  time.Duration
+	wake      chan struct{}
+	retention time.Duration
+	cleanedAt time.Time
+}
+
+func NewRelay(store *repository.Store, interval time.Duration, sinks ...Sink) *Relay {
+	return &Relay{store: store, sinks: sinks, interval: interval, wake: make(chan struct{}, 1)}
+}
+
+// Включает удаление событий старше retention, обработанных всеми получателями этого Relay.
+// Отметки получателей других экземпляров (events) удаляются вместе с событиями, поэтому набор
+// остальных получателей должен совпадать на всех экземплярах. 0 - события не удаляются
+func (r *Relay) SetRetention(retention time.Duration) {
+	r.retention = retention
+}
+
+func (r *Relay) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Передает события до отмены ctx. После отмены передает оставшиеся события и завершается,
+// поэтому при остановке сервиса его отменяют после того, как перестали приниматься запросы
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		r.relaySinks()
+		r.cleanupIfDue()
+		select {
+		case <-ctx.Done():
+			r.relaySinks()
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		}
+	}
+}
+
+func (r *Relay) relaySinks() {
+	for _, sink := range r.sinks {
+		r.relayAll(sink)
+	}
+}
+
+func (r *Relay) cleanupIfDue() {
+	if r.retention <= 0 || time.Since(r.cleanedAt) < cleanupInterval {
+		return
+	}
+	err := r.cleanup()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	r.cleanedAt = time.Now()
+}
+
+func (r *Relay) cleanup() error {
+	names := make([]string, 0, len(r.sinks))
+	for _, sink := range r.sinks {
+		names = append(names, sink.Name())
+	}
+	deleted, err := r.store.Outbox.DeleteProcessed(names, r.retention)
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Infof("Outbox: deleted %d processed events", deleted)
+	}
+	return nil
+}
+
+// При ошибке получателя события остаются в outbox и передаются повторно при следующем опросе
+func (r *Relay) relayAll(sink Sink) {
+	for {
+		relayed, err := r.relay(sink)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		if relayed < relayBatchSize {
+			return
+		}
+	}
+}
+
+func (r *Relay) relay(sink Sink) (int, error) {
+	if preparer, ok := sink.(Preparer); ok {
+		outboxEvents, err := r.store.Outbox.ListPending(sink.Name(), relayBatchSize)
+		if err != nil {
+			return 0, err
+		}
+		err = preparer.Prepare(outboxEvents)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var relayed int
+	var afterCommit []func()
+	err := r.store.InTx(func(tx *repository.Store) error {
+		relayed, afterCommit = 0, nil
+		outboxEvents, err := tx.Outbox.ListPending(sink.Name(), relayBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, event := range outboxEvents {
+			after, err := sink.Handle(tx, event)
+			if err == ErrNotPrepared {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if after != nil {
+				afterCommit = append(afterCommit, after)
+			}
+			err = tx.Outbox.MarkProcessed(sink.Name(), event.Id)
+			if err != nil {
+				return err
+			}
+			relayed++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, after := range afterCommit {
+		after()
+	}
+	return relayed, nil
+}
@@ -0,0 +1,234 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"avitoTask/internal/events"
+	"avitoTask/internal/repository"
+	"avitoTask/internal/repository/memory"
+)
+
+func addEvents(t *testing.T, store *repository.Store, count int) {
+	t.Helper()
+	for range count {
+		err := store.Outbox.Create(&repository.OutboxEvent{EventType: "tender_closed", Payload: `{}`})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readRecords(t *testing.T, path string) []fileRecord {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var records []fileRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record fileRecord
+		err := json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func pending(t *testing.T, store *repository.Store, sink string) int {
+	t.Helper()
+	outboxEvents, err := store.Outbox.ListPending(sink, relayBatchSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(outboxEvents)
+}
+
+// Получатель, который при подготовке добавляет в outbox новое событие. Хранилище в памяти блокируется
+// на время транзакции, поэтому вызов Prepare внутри транзакции привел бы к взаимной блокировке
+type lateEventSink struct {
+	*FileSink
+	store *repository.Store
+	added bool
+}
+
+func (s *lateEventSink) Prepare(outboxEvents []repository.OutboxEvent) error {
+	err := s.FileSink.Prepare(outboxEvents)
+	if err != nil || s.added {
+		return err
+	}
+	s.added = true
+	return s.store.Outbox.Create(&repository.OutboxEvent{EventType: "tender_closed", Payload: `{}`})
+}
+
+func TestFileSinkWritesBeforeTransaction(t *testing.T) {
+	store := memory.NewStore(nil)
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	fileSink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fileSink.Close()
+	addEvents(t, store, 2)
+	relay := NewRelay(store, time.Second)
+	sink := &lateEventSink{FileSink: fileSink, store: store}
+
+	relayed, err := relay.relay(sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if relayed != 2 || pending(t, store, sink.Name()) != 1 {
+		t.Fatalf("обработано %d событий, ожидалось 2, а поступившее после записи - в очереди", relayed)
+	}
+
+	relayed, err = relay.relay(sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if relayed != 1 || pending(t, store, sink.Name()) != 0 {
+		t.Fatalf("обработано %d событий при повторном опросе, ожидалось 1", relayed)
+	}
+	if records := readRecords(t, path); len(records) != 3 {
+		t.Fatalf("в файле %d событий, ожидалось 3", len(records))
+	}
+}
+
+// События, записанные до сбоя, но не отмеченные обработанными, не дублируются после перезапуска
+func TestFileSinkSkipsWrittenEventsAfterRestart(t *testing.T) {
+	store := memory.NewStore(nil)
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	addEvents(t, store, 2)
+	outboxEvents, err := store.Outbox.ListPending("file", relayBatchSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := NewFileSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = first.Prepare(outboxEvents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Close()
+
+	second, err := NewFileSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+	relayed, err := NewRelay(store, time.Second).relay(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if relayed != 2 {
+		t.Fatalf("обработано %d событий, ожидалось 2", relayed)
+	}
+	if records := readRecords(t, path); len(records) != 2 {
+		t.Fatalf("в файле %d событий, ожидалось 2", len(records))
+	}
+}
+
+// После отмены контекста Run передает оставшиеся события и завершается
+func TestRelayDrainsOnCancel(t *testing.T) {
+	store := memory.NewStore(nil)
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	fileSink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fileSink.Close()
+	relay := NewRelay(store, time.Hour, fileSink)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+	addEvents(t, store, 3)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run не завершился после отмены")
+	}
+	if pending(t, store, fileSink.Name()) != 0 {
+		t.Fatal("после остановки в outbox остались необработанные события")
+	}
+	if records := readRecords(t, path); len(records) != 3 {
+		t.Fatalf("в файле %d событий, ожидалось 3", len(records))
+	}
+}
+
+type recordingPublisher struct {
+	published []events.Event
+}
+
+func (p *recordingPublisher) Publish(event events.Event) {
+	p.published = append(p.published, event)
+}
+
+// Каждый экземпляр передает своим подписчикам события, добавленные после его запуска
+func TestBrokerSinkPerInstance(t *testing.T) {
+	store := memory.NewStore(nil)
+	addEvents(t, store, 1)
+	var publishers []*recordingPublisher
+	var sinks []Sink
+	for range 2 {
+		publisher := &recordingPublisher{}
+		sink, err := NewBrokerSink(store, publisher)
+		if err != nil {
+			t.Fatal(err)
+		}
+		publishers = append(publishers, publisher)
+		sinks = append(sinks, sink)
+	}
+	if sinks[0].Name() == sinks[1].Name() {
+		t.Fatal("у экземпляров совпадают имена получателей")
+	}
+	addEvents(t, store, 2)
+
+	relay := NewRelay(store, time.Second, sinks...)
+	relay.relaySinks()
+	for i, publisher := range publishers {
+		if len(publisher.published) != 2 {
+			t.Fatalf("экземпляр %d передал %d событий, ожидалось 2", i, len(publisher.published))
+		}
+		if pending(t, store, sinks[i].Name()) != 0 {
+			t.Fatalf("у экземпляра %d остались необработанные события", i)
+		}
+	}
+}
+
+// Удаляются только события, обработанные всеми получателями
+func TestRelayCleanupKeepsPendingEvents(t *testing.T) {
+	store := memory.NewStore(nil)
+	addEvents(t, store, 2)
+	relay := NewRelay(store, time.Second, LogSink{})
+	relay.SetRetention(time.Nanosecond)
+	relay.relaySinks()
+	addEvents(t, store, 1)
+	time.Sleep(time.Millisecond)
+
+	err := relay.cleanup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending(t, store, "log") != 1 {
+		t.Fatal("необработанное событие удалено")
+	}
+	if pending(t, store, "other") != 1 {
+		t.Fatal("обработанные события не удалены")
+	}
+}
@@ -0,0 +1,184 @@
+package outbox
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"avitoTask/internal/events"
+	"avitoTask/internal/repository"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// Пишет события в лог сервиса. Запись выполняется после фиксации транзакции, отмечающей событие
+// обработанным, поэтому доставка не более одного раза: при сбое между ними событие в лог не попадет.
+// Для доставки без потерь используется FileSink
+type LogSink struct{}
+
+func (LogSink) Name() string {
+	return "log"
+}
+
+func (LogSink) Handle(tx *repository.Store, event repository.OutboxEvent) (func(), error) {
+	return func() {
+		log.WithFields(log.Fields{
+			"eventId":   event.Id,
+			"eventType": event.EventType,
+			"tenderId":  event.TenderId,
+		}).Info(event.Payload)
+	}, nil
+}
+
+// Передает события подписчикам потока /api/events этого экземпляра сервиса. Подписчики подключены
+// к каждому экземпляру отдельно, поэтому события отмечаются обработанными под именем с id экземпляра.
+// События, добавленные до запуска, отмечаются без передачи: их подписчиков уже нет. Доставка
+// не более одного раза: события передаются после фиксации транзакции и при сбое теряются,
+// подписчики после переподключения получают только новые события
+type BrokerSink struct {
+	publisher events.Publisher
+	name      string
+	startId   int64
+}
+
+func NewBrokerSink(store *repository.Store, publisher events.Publisher) (*BrokerSink, error) {
+	startId, err := store.Outbox.LastId()
+	if err != nil {
+		return nil, err
+	}
+	return &BrokerSink{publisher: publisher, name: "events:" + uuid.NewString(), startId: startId}, nil
+}
+
+func (s *BrokerSink) Name() string {
+	return s.name
+}
+
+func (s *BrokerSink) Handle(tx *repository.Store, event repository.OutboxEvent) (func(), error) {
+	if event.Id <= s.startId {
+		return nil, nil
+	}
+	var brokerEvent events.Event
+	err := json.Unmarshal([]byte(event.Payload), &brokerEvent)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		s.publisher.Publish(brokerEvent)
+	}, nil
+}
+
+// Строка файла FileSink
+type fileRecord struct {
+	Id        int64           `json:"id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt string          `json:"createdAt"`
+}
+
+// Дописывает события в файл по одному JSON на строку. События записываются в Prepare до транзакции,
+// отмечающей их обработанными, поэтому при сбое между ними событие уже есть в файле. Записанные id
+// хранятся в памяти и читаются из файла при запуске, повторно такие события не пишутся
+type FileSink struct {
+	mu      sync.Mutex
+	file    *os.File
+	written map[int64]struct{}
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	written, err := readWritten(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &FileSink{file: file, written: written}, nil
+}
+
+// Id записанных событий. Недописанная при сбое строка пропускается и завершается переводом строки,
+// событие из нее будет записано заново
+func readWritten(file *os.File) (map[int64]struct{}, error) {
+	written := map[int64]struct{}{}
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				_, err = file.Write([]byte{'\n'})
+				return written, err
+			}
+			return written, nil
+		} else if err != nil {
+			return nil, err
+		}
+		var record fileRecord
+		if json.Unmarshal(line, &record) == nil {
+			written[record.Id] = struct{}{}
+		}
+	}
+}
+
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+// Дописывает незаписанные события одним блоком и сбрасывает файл на диск
+func (s *FileSink) Prepare(outboxEvents []repository.OutboxEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var lines []byte
+	var ids []int64
+	for _, event := range outboxEvents {
+		if _, ok := s.written[event.Id]; ok {
+			continue
+		}
+		line, err := json.Marshal(fileRecord{
+			Id:        event.Id,
+			Type:      event.EventType,
+			Payload:   json.RawMessage(event.Payload),
+			CreatedAt: event.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		lines = append(append(lines, line...), '\n')
+		ids = append(ids, event.Id)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := s.file.Write(lines)
+	if err != nil {
+		return err
+	}
+	err = s.file.Sync()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		s.written[id] = struct{}{}
+	}
+	return nil
+}
+
+func (s *FileSink) Handle(tx *repository.Store, event repository.OutboxEvent) (func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.written[event.Id]; !ok {
+		return nil, ErrNotPrepared
+	}
+	return nil, nil
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
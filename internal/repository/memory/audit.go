@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"avitoTask/internal/repository"
+)
+
+type auditRepository struct {
+	*state
+}
+
+func (r *auditRepository) Create(entry *repository.AuditEntry) error {
+	defer r.lock()()
+	entry.Id = int64(len(r.data.audit) + 1)
+	entry.CreatedAt = now()
+	r.data.audit = append(r.data.audit, *entry)
+	return nil
+}
+
+func (r *auditRepository) List(filter repository.AuditFilter, limit, offset int) ([]repository.AuditEntry, error) {
+	defer r.lock()()
+	entries := []repository.AuditEntry{}
+	// Записи хранятся в порядке создания, новые первыми
+	for i := len(r.data.audit) - 1; i >= 0; i-- {
+		match, err := matchAuditEntry(r.data.audit[i], filter)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			entries = append(entries, r.data.audit[i])
+		}
+	}
+	return page(entries, limit, offset), nil
+}
+
+func matchAuditEntry(entry repository.AuditEntry, filter repository.AuditFilter) (bool, error) {
+	if entry.OrganizationId != filter.OrganizationId {
+		return false, nil
+	}
+	if filter.EntityType != "" && filter.EntityType != entry.EntityType {
+		return false, nil
+	}
+	if filter.EntityId != "" && filter.EntityId != entry.EntityId {
+		return false, nil
+	}
+	if filter.Actor != "" && filter.Actor != entry.Actor {
+		return false, nil
+	}
+	createdAt, err := parseTime(entry.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+	if filter.From != nil && createdAt.Before(*filter.From) {
+		return false, nil
+	}
+	return filter.To == nil || !createdAt.After(*filter.To), nil
+}
@@ -0,0 +1,292 @@
+package memory
+
+import (
+	"cmp"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"avitoTask/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type bidRepository struct {
+	*state
+}
+
+// Допустимые переходы статусов предложения, дублируют таблицу bid_status_transition
+var bidStatusTransitions map[string][]string = map[string][]string{
+	"Created":   {"Published", "Canceled"},
+	"Published": {"Canceled"},
+}
+
+// Аналог bidAuthorCondition: автор предложения пользователь лично или организация, в которой у него есть роль
+func (d *data) isBidAuthor(bid repository.Bid, userId string) bool {
+	if bid.AuthorType == "User" {
+		return bid.AuthorId == userId
+	}
+	return d.isMember(bid.AuthorId, userId)
+}
+
+// Аналог триггера check_lifecycle
+func (d *data) checkBidLifecycle(old repository.Bid, status string) error {
+	tenderStatus := d.tenders[old.TenderId].Status
+	if old.Decision != nil || tenderStatus == "Closed" {
+		return &repository.ConflictError{Reason: "Предложение недоступно для изменения"}
+	}
+	if status == old.Status {
+		return nil
+	}
+	if !slices.Contains(bidStatusTransitions[old.Status], status) {
+		return &repository.ConflictError{
+			Reason: fmt.Sprintf("Недопустимый переход статуса предложения: %s -> %s", old.Status, status),
+		}
+	}
+	if status == "Published" && tenderStatus != "Published" {
+		return &repository.ConflictError{Reason: "Предложения можно публиковать только для опубликованных тендеров"}
+	}
+	return nil
+}
+
+func (r *bidRepository) Exists(bidId string) error {
+	_, err := r.Get(bidId)
+	return err
+}
+
+func (r *bidRepository) Get(bidId string) (*repository.Bid, error) {
+	defer r.lock()()
+	bid, ok := r.data.bids[bidId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &bid, nil
+}
+
+func (r *bidRepository) ListByTender(tenderId string, filter repository.BidFilter, page repository.Page) ([]repository.Bid, error) {
+	defer r.lock()()
+	return paginate(r.data.tenderBids(tenderId, filter), page)
+}
+
+func (r *bidRepository) CountByTender(tenderId string, filter repository.BidFilter) (int, error) {
+	defer r.lock()()
+	return len(r.data.tenderBids(tenderId, filter)), nil
+}
+
+// Аналог bidViewCondition
+func (d *data) filterBids(filter repository.BidFilter) []repository.Bid {
+	bids := []repository.Bid{}
+	viewerId, _ := d.employeeId(filter.ViewerUsername)
+	for _, bid := range d.bids {
+		if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, bid.Status) {
+			continue
+		}
+		if d.isBidAuthor(bid, viewerId) ||
+			bid.Status == "Published" && d.hasRole(d.tenders[bid.TenderId].OrganizationId, viewerId, filter.ViewerRoles) {
+			bids = append(bids, bid)
+		}
+	}
+	return bids
+}
+
+func (d *data) tenderBids(tenderId string, filter repository.BidFilter) []repository.Bid {
+	return slices.DeleteFunc(d.filterBids(filter), func(bid repository.Bid) bool {
+		return bid.TenderId != tenderId
+	})
+}
+
+func (r *bidRepository) Search(query string, filter repository.BidFilter, limit, offset int) ([]repository.FoundBid, error) {
+	defer r.lock()()
+	found := []repository.FoundBid{}
+	for _, bid := range r.data.filterBids(filter) {
+		if match, ok := searchText(query, bid.Name, bid.Description); ok {
+			found = append(found, repository.FoundBid{Bid: bid, SearchMatch: match})
+		}
+	}
+	slices.SortFunc(found, func(a, b repository.FoundBid) int {
+		return cmp.Or(cmp.Compare(b.Rank, a.Rank), cmp.Compare(a.Id, b.Id))
+	})
+	return page(found, limit, offset), nil
+}
+
+func (r *bidRepository) CountByStatus(tenderId string, filter repository.BidFilter) (map[string]int, error) {
+	defer r.lock()()
+	counts := map[string]int{}
+	for _, bid := range r.data.tenderBids(tenderId, filter) {
+		counts[bid.Status]++
+	}
+	return counts, nil
+}
+
+func (r *bidRepository) GetApproved(tenderId string) (*repository.Bid, error) {
+	defer r.lock()()
+	for _, bid := range r.data.bids {
+		if bid.TenderId == tenderId && bid.Decision != nil && *bid.Decision == "Approved" {
+			return &bid, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *bidRepository) ListByMember(username string, roles []string, page repository.Page) ([]repository.Bid, error) {
+	defer r.lock()()
+	return paginate(r.data.memberBids(username, roles), page)
+}
+
+func (r *bidRepository) CountByMember(username string, roles []string) (int, error) {
+	defer r.lock()()
+	return len(r.data.memberBids(username, roles)), nil
+}
+
+func (d *data) memberBids(username string, roles []string) []repository.Bid {
+	bids := []repository.Bid{}
+	userId, err := d.employeeId(username)
+	if err != nil {
+		return bids
+	}
+	for _, bid := range d.bids {
+		if bid.AuthorType == "User" && bid.AuthorId == userId ||
+			bid.AuthorType == "Organization" && d.hasRole(bid.AuthorId, userId, roles) {
+			bids = append(bids, bid)
+		}
+	}
+	return bids
+}
+
+// Аналог триггера check_tender_status
+func (r *bidRepository) Create(bid *repository.Bid) error {
+	defer r.lock()()
+	if r.data.tenders[bid.TenderId].Status != "Published" {
+		return &repository.ConflictError{Reason: "Предложения можно создавать только для опубликованных тендеров"}
+	}
+	createdAt, err := normalizeTime(bid.CreatedAt)
+	if err != nil {
+		return err
+	}
+	bid.Id = uuid.NewString()
+	bid.CreatedAt = createdAt
+	r.data.bids[bid.Id] = *bid
+	return nil
+}
+
+func (r *bidRepository) UpdateStatus(bidId, status string, change repository.Change) error {
+	defer r.lock()()
+	bid, ok := r.data.bids[bidId]
+	if !ok {
+		return nil
+	}
+	err := r.data.checkBidLifecycle(bid, status)
+	if err != nil {
+		return err
+	}
+	updated := bid
+	updated.Status = status
+	r.data.updateBid(bid, updated, change)
+	return nil
+}
+
+func (r *bidRepository) Update(bid *repository.Bid, change repository.Change) error {
+	defer r.lock()()
+	old, ok := r.data.bids[bid.Id]
+	if !ok {
+		return nil
+	}
+	err := r.data.checkBidLifecycle(old, old.Status)
+	if err != nil {
+		return err
+	}
+	updated := old
+	updated.Name = bid.Name
+	updated.Description = bid.Description
+	r.data.updateBid(old, updated, change)
+	return nil
+}
+
+// Аналог триггера write_hist: при изменении полей предыдущая версия сохраняется в истории
+func (d *data) updateBid(old, updated repository.Bid, change repository.Change) {
+	if old.Name == updated.Name && old.Description == updated.Description && old.Status == updated.Status {
+		return
+	}
+	d.bidHist[old.Id] = append(d.bidHist[old.Id], repository.BidVersion{
+		BidId:       old.Id,
+		Version:     old.Version,
+		Name:        old.Name,
+		Description: old.Description,
+		Status:      old.Status,
+		ChangedAt:   now(),
+		ChangedBy:   change.Username,
+		ChangeKind:  change.Kind,
+		Comment:     change.Comment,
+	})
+	updated.Version++
+	d.bids[old.Id] = updated
+}
+
+func (r *bidRepository) SetDecision(bidId, decision string) error {
+	defer r.lock()()
+	bid, ok := r.data.bids[bidId]
+	if !ok {
+		return nil
+	}
+	err := r.data.checkBidLifecycle(bid, bid.Status)
+	if err != nil {
+		return err
+	}
+	bid.Decision = &decision
+	r.data.bids[bidId] = bid
+	return nil
+}
+
+func (r *bidRepository) GetVersion(bidId string, version int) (*repository.BidVersion, error) {
+	defer r.lock()()
+	for _, bidVersion := range r.data.bidHist[bidId] {
+		if bidVersion.Version == version {
+			return &bidVersion, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *bidRepository) ListVersions(bidId string) ([]repository.BidVersion, error) {
+	defer r.lock()()
+	return append([]repository.BidVersion{}, r.data.bidHist[bidId]...), nil
+}
+
+func (r *bidRepository) CreateFeedback(feedback *repository.BidFeedback) error {
+	defer r.lock()()
+	feedback.Id = uuid.NewString()
+	feedback.CreatedAt = now()
+	r.data.feedback = append(r.data.feedback, *feedback)
+	return nil
+}
+
+func (r *bidRepository) ListAuthorFeedback(authorUsername string, limit, offset int) ([]repository.BidFeedback, error) {
+	defer r.lock()()
+	feedback := []repository.BidFeedback{}
+	userId, err := r.data.employeeId(authorUsername)
+	if err != nil {
+		return feedback, nil
+	}
+	// Отзывы хранятся в порядке создания, новые первыми
+	for i := len(r.data.feedback) - 1; i >= 0; i-- {
+		bid := r.data.bids[r.data.feedback[i].BidId]
+		if r.data.isBidAuthor(bid, userId) {
+			feedback = append(feedback, r.data.feedback[i])
+		}
+	}
+	return page(feedback, limit, offset), nil
+}
+
+func (r *bidRepository) ExistsByAuthor(tenderId, authorUsername string) error {
+	defer r.lock()()
+	userId, err := r.data.employeeId(authorUsername)
+	if err != nil {
+		return sql.ErrNoRows
+	}
+	for _, bid := range r.data.bids {
+		if bid.TenderId == tenderId && r.data.isBidAuthor(bid, userId) {
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
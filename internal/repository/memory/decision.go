@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"strconv"
+
+	"avitoTask/internal/repository"
+)
+
+type decisionRepository struct {
+	*state
+}
+
+func (r *decisionRepository) Create(decision *repository.BidDecision) error {
+	defer r.lock()()
+	r.data.decisionSeq++
+	decision.Id = strconv.Itoa(r.data.decisionSeq)
+	r.data.decisions = append(r.data.decisions, *decision)
+	return nil
+}
+
+func (r *decisionRepository) CountByUser(bidId, username string) (int, error) {
+	defer r.lock()()
+	var decisionCnt int
+	for _, decision := range r.data.decisions {
+		if decision.BidId == bidId && decision.Username == username {
+			decisionCnt++
+		}
+	}
+	return decisionCnt, nil
+}
+
+func (r *decisionRepository) ListByBid(bidId string) ([]repository.BidDecision, error) {
+	defer r.lock()()
+	decisions := []repository.BidDecision{}
+	for _, decision := range r.data.decisions {
+		if decision.BidId == bidId {
+			decisions = append(decisions, decision)
+		}
+	}
+	return decisions, nil
+}
+
+func (r *decisionRepository) CountApproved(bidId string) (int, error) {
+	defer r.lock()()
+	var decisionCnt int
+	for _, decision := range r.data.decisions {
+		if decision.BidId == bidId && decision.Decision == "Approved" {
+			decisionCnt++
+		}
+	}
+	return decisionCnt, nil
+}
@@ -0,0 +1,28 @@
+package memory
+
+import (
+	"database/sql"
+)
+
+type employeeRepository struct {
+	*state
+}
+
+func (r *employeeRepository) Exists(username string) error {
+	_, err := r.GetId(username)
+	return err
+}
+
+func (r *employeeRepository) GetId(username string) (string, error) {
+	defer r.lock()()
+	return r.data.employeeId(username)
+}
+
+func (d *data) employeeId(username string) (string, error) {
+	for _, employee := range d.employees {
+		if employee.Username == username {
+			return employee.Id, nil
+		}
+	}
+	return "", sql.ErrNoRows
+}
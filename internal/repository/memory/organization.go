@@ -0,0 +1,182 @@
+package memory
+
+import (
+	"cmp"
+	"database/sql"
+	"slices"
+
+	"avitoTask/internal/repository"
+)
+
+type organizationRepository struct {
+	*state
+}
+
+type memberRole struct {
+	UserId string
+	Role   string
+}
+
+// Аналог представления organization_member_role
+func (d *data) memberRoles(organizationId string) []memberRole {
+	var roles []memberRole
+	for _, responsible := range d.responsibles {
+		if responsible.OrganizationId == organizationId {
+			roles = append(roles, memberRole{UserId: responsible.UserId, Role: "owner"})
+		}
+	}
+	for _, grantedRole := range d.employeeRoles {
+		if grantedRole.OrganizationId == organizationId {
+			roles = append(roles, memberRole{UserId: grantedRole.UserId, Role: grantedRole.Role})
+		}
+	}
+	slices.SortFunc(roles, func(a, b memberRole) int {
+		return cmp.Or(cmp.Compare(a.UserId, b.UserId), cmp.Compare(a.Role, b.Role))
+	})
+	return slices.Compact(roles)
+}
+
+func (d *data) isMember(organizationId, userId string) bool {
+	return slices.ContainsFunc(d.memberRoles(organizationId), func(role memberRole) bool {
+		return role.UserId == userId
+	})
+}
+
+func (d *data) hasRole(organizationId, userId string, roles []string) bool {
+	return slices.ContainsFunc(d.memberRoles(organizationId), func(role memberRole) bool {
+		return role.UserId == userId && slices.Contains(roles, role.Role)
+	})
+}
+
+func (r *organizationRepository) Exists(organizationId string) error {
+	defer r.lock()()
+	if _, ok := r.data.organizations[organizationId]; !ok {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *organizationRepository) Get(organizationId string) (*repository.Organization, error) {
+	defer r.lock()()
+	organization, ok := r.data.organizations[organizationId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &repository.Organization{
+		Id:          organization.Id,
+		Name:        organization.Name,
+		Description: organization.Description,
+		Type:        organization.Type,
+	}, nil
+}
+
+func (r *organizationRepository) GetMemberRoles(organizationId, username string) ([]string, error) {
+	defer r.lock()()
+	roles := []string{}
+	userId, err := r.data.employeeId(username)
+	if err != nil {
+		return roles, nil
+	}
+	for _, role := range r.data.memberRoles(organizationId) {
+		if role.UserId == userId {
+			roles = append(roles, role.Role)
+		}
+	}
+	return roles, nil
+}
+
+func (r *organizationRepository) CountMembersWithRoles(organizationId string, roles []string) (int, error) {
+	defer r.lock()()
+	var userIds []string
+	for _, role := range r.data.memberRoles(organizationId) {
+		if slices.Contains(roles, role.Role) && !slices.Contains(userIds, role.UserId) {
+			userIds = append(userIds, role.UserId)
+		}
+	}
+	return len(userIds), nil
+}
+
+func (r *organizationRepository) GetQuorum(organizationId string) (int, error) {
+	defer r.lock()()
+	quorum, ok := r.data.quorums[organizationId]
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	return quorum, nil
+}
+
+func (r *organizationRepository) SetQuorum(organizationId string, quorum int) error {
+	defer r.lock()()
+	r.data.quorums[organizationId] = quorum
+	return nil
+}
+
+func (r *organizationRepository) ListRoles(organizationId string) ([]repository.OrganizationRole, error) {
+	defer r.lock()()
+	roles := []repository.OrganizationRole{}
+	for _, role := range r.data.memberRoles(organizationId) {
+		organizationRole := repository.OrganizationRole{
+			Username: r.data.employees[role.UserId].Username,
+			Role:     role.Role,
+		}
+		if grantedRole, ok := r.data.employeeRole(organizationId, role.UserId, role.Role); ok {
+			organizationRole.GrantedBy = &grantedRole.GrantedBy
+			organizationRole.GrantedAt = &grantedRole.GrantedAt
+		}
+		roles = append(roles, organizationRole)
+	}
+	slices.SortFunc(roles, func(a, b repository.OrganizationRole) int {
+		return cmp.Or(cmp.Compare(a.Username, b.Username), cmp.Compare(a.Role, b.Role))
+	})
+	return roles, nil
+}
+
+func (d *data) employeeRole(organizationId, userId, role string) (employeeRole, bool) {
+	for _, grantedRole := range d.employeeRoles {
+		if grantedRole.OrganizationId == organizationId && grantedRole.UserId == userId && grantedRole.Role == role {
+			return grantedRole, true
+		}
+	}
+	return employeeRole{}, false
+}
+
+func (r *organizationRepository) GrantRole(organizationId, username, role, grantedBy string) (*repository.OrganizationRole, error) {
+	defer r.lock()()
+	userId, err := r.data.employeeId(username)
+	if err != nil {
+		return nil, err
+	}
+	grantedRole, ok := r.data.employeeRole(organizationId, userId, role)
+	if !ok {
+		grantedRole = employeeRole{
+			OrganizationId: organizationId,
+			UserId:         userId,
+			Role:           role,
+			GrantedBy:      grantedBy,
+			GrantedAt:      now(),
+		}
+		r.data.employeeRoles = append(r.data.employeeRoles, grantedRole)
+	}
+	return &repository.OrganizationRole{
+		Username:  username,
+		Role:      role,
+		GrantedBy: &grantedRole.GrantedBy,
+		GrantedAt: &grantedRole.GrantedAt,
+	}, nil
+}
+
+func (r *organizationRepository) RevokeRole(organizationId, username, role string) error {
+	defer r.lock()()
+	userId, err := r.data.employeeId(username)
+	if err != nil {
+		return err
+	}
+	index := slices.IndexFunc(r.data.employeeRoles, func(grantedRole employeeRole) bool {
+		return grantedRole.OrganizationId == organizationId && grantedRole.UserId == userId && grantedRole.Role == role
+	})
+	if index < 0 {
+		return sql.ErrNoRows
+	}
+	r.data.employeeRoles = slices.Delete(r.data.employeeRoles, index, index+1)
+	return nil
+}
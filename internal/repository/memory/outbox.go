@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"time"
+
+	"avitoTask/internal/repository"
+)
+
+type outboxRepository struct {
+	*state
+}
+
+// Id берется из текущего времени, чтобы не повторяться между запусками: FileSink хранит id записанных событий
+func (r *outboxRepository) Create(event *repository.OutboxEvent) error {
+	defer r.lock()()
+	event.Id = time.Now().UnixNano()
+	if n := len(r.data.outbox); n > 0 && event.Id <= r.data.outbox[n-1].Id {
+		event.Id = r.data.outbox[n-1].Id + 1
+	}
+	event.CreatedAt = now()
+	r.data.outbox = append(r.data.outbox, *event)
+	return nil
+}
+
+// Транзакции выполняются последовательно, поэтому блокировать выбранные события не нужно
+func (r *outboxRepository) ListPending(sink string, limit int) ([]repository.OutboxEvent, error) {
+	defer r.lock()()
+	outboxEvents := []repository.OutboxEvent{}
+	for _, event := range r.data.outbox {
+		if _, ok := r.data.outboxProcessed[outboxMark{sink: sink, eventId: event.Id}]; !ok {
+			outboxEvents = append(outboxEvents, event)
+		}
+	}
+	return page(outboxEvents, limit, 0), nil
+}
+
+func (r *outboxRepository) MarkProcessed(sink string, eventId int64) error {
+	defer r.lock()()
+	r.data.outboxProcessed[outboxMark{sink: sink, eventId: eventId}] = struct{}{}
+	return nil
+}
+
+func (r *outboxRepository) LastId() (int64, error) {
+	defer r.lock()()
+	if n := len(r.data.outbox); n > 0 {
+		return r.data.outbox[n-1].Id, nil
+	}
+	return 0, nil
+}
+
+func (r *outboxRepository) DeleteProcessed(sinks []string, olderThan time.Duration) (int64, error) {
+	defer r.lock()()
+	before := time.Now().Add(-olderThan)
+	var deleted int64
+	outboxEvents := r.data.outbox[:0]
+	for _, event := range r.data.outbox {
+		createdAt, err := parseTime(event.CreatedAt)
+		if err != nil {
+			return 0, err
+		}
+		if !createdAt.Before(before) || !r.processed(sinks, event.Id) {
+			outboxEvents = append(outboxEvents, event)
+			continue
+		}
+		for mark := range r.data.outboxProcessed {
+			if mark.eventId == event.Id {
+				delete(r.data.outboxProcessed, mark)
+			}
+		}
+		deleted++
+	}
+	r.data.outbox = outboxEvents
+	return deleted, nil
+}
+
+func (r *outboxRepository) processed(sinks []string, eventId int64) bool {
+	for _, sink := range sinks {
+		if _, ok := r.data.outboxProcessed[outboxMark{sink: sink, eventId: eventId}]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"avitoTask/internal/repository"
+)
+
+var wordRegexp = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// Упрощенный аналог полнотекстового поиска: запись подходит, если название или описание содержат
+// все слова запроса без учета регистра. Совпадения в названии весомее совпадений в описании
+func searchText(query, name, description string) (repository.SearchMatch, bool) {
+	terms := wordRegexp.FindAllString(strings.ToLower(query), -1)
+	if len(terms) == 0 {
+		return repository.SearchMatch{}, false
+	}
+	lowerName, lowerDescription := strings.ToLower(name), strings.ToLower(description)
+	var rank float64
+	for _, term := range terms {
+		nameCnt := strings.Count(lowerName, term)
+		descriptionCnt := strings.Count(lowerDescription, term)
+		if nameCnt+descriptionCnt == 0 {
+			return repository.SearchMatch{}, false
+		}
+		rank += float64(nameCnt) + 0.4*float64(descriptionCnt)
+	}
+	return repository.SearchMatch{
+		Rank:                 rank,
+		NameHighlight:        highlight(name, terms),
+		DescriptionHighlight: highlight(description, terms),
+	}, true
+}
+
+// Экранирует текст как HTML и выделяет тегами <b></b> слова, содержащие одно из слов запроса
+func highlight(text string, terms []string) string {
+	var result strings.Builder
+	last := 0
+	for _, bounds := range wordRegexp.FindAllStringIndex(text, -1) {
+		result.WriteString(html.EscapeString(text[last:bounds[0]]))
+		word := text[bounds[0]:bounds[1]]
+		if containsTerm(strings.ToLower(word), terms) {
+			result.WriteString("<b>" + word + "</b>")
+		} else {
+			result.WriteString(word)
+		}
+		last = bounds[1]
+	}
+	result.WriteString(html.EscapeString(text[last:]))
+	return result.String()
+}
+
+func containsTerm(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.Contains(word, term) {
+			return true
+		}
+	}
+	return false
+}
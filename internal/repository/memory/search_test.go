@@ -0,0 +1,19 @@
+package memory
+
+import "testing"
+
+// Пользовательский текст в выделении экранируется, тегами остаются только выделенные совпадения
+func TestSearchHighlightEscapesHtml(t *testing.T) {
+	match, ok := searchText("доставка", `Доставка <script>alert("x")</script>`, `Тендер & доставка 'грузов'`)
+	if !ok {
+		t.Fatal("запись не найдена")
+	}
+	expectedName := `<b>Доставка</b> &lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;`
+	if match.NameHighlight != expectedName {
+		t.Errorf("название %s, ожидалось %s", match.NameHighlight, expectedName)
+	}
+	expectedDescription := `Тендер &amp; <b>доставка</b> &#39;грузов&#39;`
+	if match.DescriptionHighlight != expectedDescription {
+		t.Errorf("описание %s, ожидалось %s", match.DescriptionHighlight, expectedDescription)
+	}
+}
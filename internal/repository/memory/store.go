@@ -0,0 +1,312 @@
+package memory
+
+import (
+	"cmp"
+	"encoding/json"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"avitoTask/internal/repository"
+)
+
+// Сотрудники, организации и ответственные не создаются через API, поэтому задаются при запуске
+type Seed struct {
+	Employees                []Employee                `json:"employees"`
+	Organizations            []Organization            `json:"organizations"`
+	OrganizationResponsibles []OrganizationResponsible `json:"organizationResponsibles"`
+	OrganizationQuorums      []OrganizationQuorum      `json:"organizationQuorums"`
+}
+
+type Employee struct {
+	Id       string `json:"id"`
+	Username string `json:"username"`
+}
+
+type Organization struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+}
+
+type OrganizationResponsible struct {
+	OrganizationId string `json:"organizationId"`
+	UserId         string `json:"userId"`
+}
+
+type OrganizationQuorum struct {
+	OrganizationId string `json:"organizationId"`
+	Quorum         int    `json:"quorum"`
+}
+
+type employeeRole struct {
+	OrganizationId string
+	UserId         string
+	Role           string
+	GrantedBy      string
+	GrantedAt      string
+}
+
+// Аналог строки outbox_event_sink
+type outboxMark struct {
+	sink    string
+	eventId int64
+}
+
+// Содержимое хранилища, аналог таблиц бд
+type data struct {
+	employees       map[string]Employee
+	organizations   map[string]Organization
+	responsibles    []OrganizationResponsible
+	employeeRoles   []employeeRole
+	quorums         map[string]int
+	tenders         map[string]repository.Tender
+	tenderHist      map[string][]repository.TenderVersion
+	bids            map[string]repository.Bid
+	bidHist         map[string][]repository.BidVersion
+	decisions       []repository.BidDecision
+	decisionSeq     int
+	feedback        []repository.BidFeedback
+	webhooks        map[string]repository.Webhook
+	deliveries      []repository.WebhookDelivery
+	outbox          []repository.OutboxEvent
+	outboxProcessed map[outboxMark]struct{}
+	audit           []repository.AuditEntry
+}
+
+func (d *data) clone() data {
+	clone := *d
+	clone.employees = maps.Clone(d.employees)
+	clone.organizations = maps.Clone(d.organizations)
+	clone.responsibles = slices.Clone(d.responsibles)
+	clone.employeeRoles = slices.Clone(d.employeeRoles)
+	clone.quorums = maps.Clone(d.quorums)
+	clone.tenders = maps.Clone(d.tenders)
+	clone.tenderHist = make(map[string][]repository.TenderVersion, len(d.tenderHist))
+	for tenderId, hist := range d.tenderHist {
+		clone.tenderHist[tenderId] = slices.Clone(hist)
+	}
+	clone.bids = maps.Clone(d.bids)
+	clone.bidHist = make(map[string][]repository.BidVersion, len(d.bidHist))
+	for bidId, hist := range d.bidHist {
+		clone.bidHist[bidId] = slices.Clone(hist)
+	}
+	clone.decisions = slices.Clone(d.decisions)
+	clone.feedback = slices.Clone(d.feedback)
+	clone.webhooks = maps.Clone(d.webhooks)
+	clone.deliveries = slices.Clone(d.deliveries)
+	clone.outbox = slices.Clone(d.outbox)
+	clone.outboxProcessed = maps.Clone(d.outboxProcessed)
+	clone.audit = slices.Clone(d.audit)
+	return clone
+}
+
+// Общее состояние репозиториев. Внутри транзакции мьютекс уже захвачен InTx
+type state struct {
+	mu   *sync.Mutex
+	data *data
+	inTx bool
+}
+
+func (s *state) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func NewStore(seed *Seed) *repository.Store {
+	d := &data{
+		employees:       map[string]Employee{},
+		organizations:   map[string]Organization{},
+		quorums:         map[string]int{},
+		tenders:         map[string]repository.Tender{},
+		tenderHist:      map[string][]repository.TenderVersion{},
+		bids:            map[string]repository.Bid{},
+		bidHist:         map[string][]repository.BidVersion{},
+		webhooks:        map[string]repository.Webhook{},
+		outboxProcessed: map[outboxMark]struct{}{},
+	}
+	if seed != nil {
+		for _, employee := range seed.Employees {
+			d.employees[employee.Id] = employee
+		}
+		for _, organization := range seed.Organizations {
+			d.organizations[organization.Id] = organization
+		}
+		d.responsibles = slices.Clone(seed.OrganizationResponsibles)
+		for _, quorum := range seed.OrganizationQuorums {
+			d.quorums[quorum.OrganizationId] = quorum.Quorum
+		}
+	}
+
+	s := &state{mu: &sync.Mutex{}, data: d}
+	store := newStore(s)
+	store.Transactor = &transactor{state: s}
+	return store
+}
+
+func LoadSeed(path string) (*Seed, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var seed Seed
+	err = json.Unmarshal(content, &seed)
+	if err != nil {
+		return nil, err
+	}
+	return &seed, nil
+}
+
+func newStore(s *state) *repository.Store {
+	return &repository.Store{
+		Tenders:       &tenderRepository{state: s},
+		Bids:          &bidRepository{state: s},
+		Decisions:     &decisionRepository{state: s},
+		Employees:     &employeeRepository{state: s},
+		Organizations: &organizationRepository{state: s},
+		Webhooks:      &webhookRepository{state: s},
+		Outbox:        &outboxRepository{state: s},
+		Audit:         &auditRepository{state: s},
+	}
+}
+
+type transactor struct {
+	state *state
+}
+
+// Транзакции выполняются последовательно, при ошибке состояние восстанавливается из снимка
+func (t *transactor) InTx(fn func(tx *repository.Store) error) error {
+	t.state.mu.Lock()
+	defer t.state.mu.Unlock()
+
+	snapshot := t.state.data.clone()
+	s := &state{mu: t.state.mu, data: t.state.data, inTx: true}
+	store := newStore(s)
+	store.Transactor = &nestedTransactor{store: store}
+	err := fn(store)
+	if err != nil {
+		*t.state.data = snapshot
+		return err
+	}
+	return nil
+}
+
+// Внутри транзакции вложенные вызовы InTx выполняются в ней же
+type nestedTransactor struct {
+	store *repository.Store
+}
+
+func (t *nestedTransactor) InTx(fn func(tx *repository.Store) error) error {
+	return fn(t.store)
+}
+
+// Время в моделях хранится строкой. Хранилище записывает его в UTC с постоянным количеством знаков,
+// а сравнивает только после разбора: строки с разной точностью или смещением нельзя сравнивать как текст
+const timeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+func now() string {
+	return formatTime(time.Now())
+}
+
+func nowAfter(d time.Duration) string {
+	return formatTime(time.Now().Add(d))
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+func parseTime(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// Приводит время, переданное при создании записи, к формату хранилища
+func normalizeTime(value string) (string, error) {
+	t, err := parseTime(value)
+	if err != nil {
+		return "", err
+	}
+	return formatTime(t), nil
+}
+
+// Ключ сортировки записи: при сортировке по времени создания сравнивается разобранное время
+type sortKey struct {
+	text string
+	time time.Time
+	id   string
+}
+
+func newSortKey(key repository.PageKey, sort string) (sortKey, error) {
+	if sort != repository.SortByCreatedAt {
+		return sortKey{text: key.Key, id: key.Id}, nil
+	}
+	t, err := parseTime(key.Key)
+	if err != nil {
+		return sortKey{}, err
+	}
+	return sortKey{time: t, id: key.Id}, nil
+}
+
+func (k sortKey) compare(other sortKey) int {
+	return cmp.Or(k.time.Compare(other.time), cmp.Compare(k.text, other.text), cmp.Compare(k.id, other.id))
+}
+
+// Страница записей, упорядоченных по полю сортировки и идентификатору
+func paginate[T repository.Pageable](items []T, p repository.Page) ([]T, error) {
+	type sortedItem struct {
+		item T
+		key  sortKey
+	}
+	sorted := make([]sortedItem, 0, len(items))
+	for _, item := range items {
+		key, err := newSortKey(item.PageKey(p.Sort), p.Sort)
+		if err != nil {
+			return nil, err
+		}
+		sorted = append(sorted, sortedItem{item: item, key: key})
+	}
+	direction := 1
+	if p.Desc {
+		direction = -1
+	}
+	slices.SortFunc(sorted, func(a, b sortedItem) int {
+		return direction * a.key.compare(b.key)
+	})
+
+	start, offset := 0, p.Offset
+	if p.After != nil {
+		after, err := newSortKey(*p.After, p.Sort)
+		if err != nil {
+			return nil, err
+		}
+		start, offset = len(sorted), 0
+		for i := range sorted {
+			if direction*sorted[i].key.compare(after) > 0 {
+				start = i
+				break
+			}
+		}
+	}
+	result := make([]T, 0, len(sorted)-start)
+	for _, s := range sorted[start:] {
+		result = append(result, s.item)
+	}
+	return page(result, p.Limit, offset), nil
+}
+
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[max(offset, 0):]
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"avitoTask/internal/repository"
+	"avitoTask/internal/repository/memory"
+	"avitoTask/internal/repository/repositorytest"
+)
+
+func TestContract(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T, seed *memory.Seed) *repository.Store {
+		return memory.NewStore(seed)
+	})
+}
@@ -0,0 +1,213 @@
+package memory
+
+import (
+	"cmp"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"avitoTask/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type tenderRepository struct {
+	*state
+}
+
+// Допустимые переходы статусов тендера, дублируют таблицу tender_status_transition
+var tenderStatusTransitions map[string][]string = map[string][]string{
+	"Created":   {"Published", "Closed"},
+	"Published": {"Closed"},
+}
+
+func (r *tenderRepository) Exists(tenderId string) error {
+	_, err := r.Get(tenderId)
+	return err
+}
+
+func (r *tenderRepository) Get(tenderId string) (*repository.Tender, error) {
+	defer r.lock()()
+	tender, ok := r.data.tenders[tenderId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &tender, nil
+}
+
+func (r *tenderRepository) List(filter repository.TenderFilter, page repository.Page) ([]repository.Tender, error) {
+	defer r.lock()()
+	tenders, err := r.data.filterTenders(filter)
+	if err != nil {
+		return nil, err
+	}
+	return paginate(tenders, page)
+}
+
+func (r *tenderRepository) Count(filter repository.TenderFilter) (int, error) {
+	defer r.lock()()
+	tenders, err := r.data.filterTenders(filter)
+	return len(tenders), err
+}
+
+func (d *data) filterTenders(filter repository.TenderFilter) ([]repository.Tender, error) {
+	tenders := []repository.Tender{}
+	viewerId, _ := d.employeeId(filter.ViewerUsername)
+	for _, tender := range d.tenders {
+		match, err := matchTender(tender, filter)
+		if err != nil {
+			return nil, err
+		}
+		if match && (tender.Status == "Published" || d.hasRole(tender.OrganizationId, viewerId, filter.ViewerRoles)) {
+			tenders = append(tenders, tender)
+		}
+	}
+	return tenders, nil
+}
+
+func (r *tenderRepository) Search(query string, filter repository.TenderFilter, limit, offset int) ([]repository.FoundTender, error) {
+	defer r.lock()()
+	tenders, err := r.data.filterTenders(filter)
+	if err != nil {
+		return nil, err
+	}
+	found := []repository.FoundTender{}
+	for _, tender := range tenders {
+		if match, ok := searchText(query, tender.Name, tender.Description); ok {
+			found = append(found, repository.FoundTender{Tender: tender, SearchMatch: match})
+		}
+	}
+	slices.SortFunc(found, func(a, b repository.FoundTender) int {
+		return cmp.Or(cmp.Compare(b.Rank, a.Rank), cmp.Compare(a.Id, b.Id))
+	})
+	return page(found, limit, offset), nil
+}
+
+func matchTender(tender repository.Tender, filter repository.TenderFilter) (bool, error) {
+	if len(filter.ServiceTypes) > 0 && !slices.Contains(filter.ServiceTypes, tender.ServiceType) {
+		return false, nil
+	}
+	if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, tender.Status) {
+		return false, nil
+	}
+	if filter.OrganizationId != "" && filter.OrganizationId != tender.OrganizationId {
+		return false, nil
+	}
+	createdAt, err := parseTime(tender.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+	if filter.CreatedFrom != nil && createdAt.Before(*filter.CreatedFrom) {
+		return false, nil
+	}
+	if filter.CreatedTo != nil && createdAt.After(*filter.CreatedTo) {
+		return false, nil
+	}
+	return strings.Contains(strings.ToLower(tender.Name), strings.ToLower(filter.Name)), nil
+}
+
+func (r *tenderRepository) ListByMember(username string, roles []string, page repository.Page) ([]repository.Tender, error) {
+	defer r.lock()()
+	return paginate(r.data.memberTenders(username, roles), page)
+}
+
+func (r *tenderRepository) CountByMember(username string, roles []string) (int, error) {
+	defer r.lock()()
+	return len(r.data.memberTenders(username, roles)), nil
+}
+
+func (d *data) memberTenders(username string, roles []string) []repository.Tender {
+	tenders := []repository.Tender{}
+	userId, err := d.employeeId(username)
+	if err != nil {
+		return tenders
+	}
+	for _, tender := range d.tenders {
+		if d.hasRole(tender.OrganizationId, userId, roles) {
+			tenders = append(tenders, tender)
+		}
+	}
+	return tenders
+}
+
+func (r *tenderRepository) Create(tender *repository.Tender) error {
+	defer r.lock()()
+	createdAt, err := normalizeTime(tender.CreatedAt)
+	if err != nil {
+		return err
+	}
+	tender.Id = uuid.NewString()
+	tender.CreatedAt = createdAt
+	r.data.tenders[tender.Id] = *tender
+	return nil
+}
+
+// Аналог триггера check_status_transition
+func (r *tenderRepository) UpdateStatus(tenderId, status string, change repository.Change) error {
+	defer r.lock()()
+	tender, ok := r.data.tenders[tenderId]
+	if !ok {
+		return nil
+	}
+	if tender.Status != status && !slices.Contains(tenderStatusTransitions[tender.Status], status) {
+		return &repository.ConflictError{
+			Reason: fmt.Sprintf("Недопустимый переход статуса тендера: %s -> %s", tender.Status, status),
+		}
+	}
+	updated := tender
+	updated.Status = status
+	r.data.updateTender(tender, updated, change)
+	return nil
+}
+
+func (r *tenderRepository) Update(tender *repository.Tender, change repository.Change) error {
+	defer r.lock()()
+	old, ok := r.data.tenders[tender.Id]
+	if !ok {
+		return nil
+	}
+	updated := old
+	updated.Name = tender.Name
+	updated.Description = tender.Description
+	updated.ServiceType = tender.ServiceType
+	r.data.updateTender(old, updated, change)
+	return nil
+}
+
+// Аналог триггера write_hist: при изменении полей предыдущая версия сохраняется в истории
+func (d *data) updateTender(old, updated repository.Tender, change repository.Change) {
+	if old == updated {
+		return
+	}
+	d.tenderHist[old.Id] = append(d.tenderHist[old.Id], repository.TenderVersion{
+		TenderId:       old.Id,
+		Version:        old.Version,
+		Name:           old.Name,
+		Description:    old.Description,
+		ServiceType:    old.ServiceType,
+		Status:         old.Status,
+		OrganizationId: old.OrganizationId,
+		ChangedAt:      now(),
+		ChangedBy:      change.Username,
+		ChangeKind:     change.Kind,
+		Comment:        change.Comment,
+	})
+	updated.Version++
+	d.tenders[old.Id] = updated
+}
+
+func (r *tenderRepository) GetVersion(tenderId string, version int) (*repository.TenderVersion, error) {
+	defer r.lock()()
+	for _, tenderVersion := range r.data.tenderHist[tenderId] {
+		if tenderVersion.Version == version {
+			return &tenderVersion, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *tenderRepository) ListVersions(tenderId string) ([]repository.TenderVersion, error) {
+	defer r.lock()()
+	return append([]repository.TenderVersion{}, r.data.tenderHist[tenderId]...), nil
+}
@@ -0,0 +1,23 @@
+package memory
+
+import (
+	"testing"
+
+	"avitoTask/internal/repository"
+)
+
+// Запись с неразбираемым временем создания - ошибка хранилища, а не повод молча пропустить запись
+func TestTenderWithInvalidCreatedAt(t *testing.T) {
+	store := NewStore(nil)
+	err := store.Tenders.Create(&repository.Tender{Name: "tender", Status: "Published", CreatedAt: "01.05.2024"})
+	if err == nil {
+		t.Fatal("тендер с некорректным временем создания сохранен")
+	}
+
+	state := store.Tenders.(*tenderRepository).state
+	state.data.tenders["broken"] = repository.Tender{Id: "broken", Status: "Published", CreatedAt: "01.05.2024"}
+	_, err = store.Tenders.List(repository.TenderFilter{}, repository.Page{Limit: 10})
+	if err == nil {
+		t.Fatal("тендер с некорректным временем создания пропущен без ошибки")
+	}
+}
@@ -0,0 +1,157 @@
+package memory
+
+import (
+	"cmp"
+	"database/sql"
+	"slices"
+	"time"
+
+	"avitoTask/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type webhookRepository struct {
+	*state
+}
+
+func (r *webhookRepository) Create(webhook *repository.Webhook) error {
+	defer r.lock()()
+	webhook.Id = uuid.NewString()
+	webhook.CreatedAt = now()
+	webhook.EventTypes = slices.Clone(webhook.EventTypes)
+	r.data.webhooks[webhook.Id] = *webhook
+	return nil
+}
+
+func (r *webhookRepository) Get(webhookId string) (*repository.Webhook, error) {
+	defer r.lock()()
+	webhook, ok := r.data.webhooks[webhookId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &webhook, nil
+}
+
+func (r *webhookRepository) ListByOrganization(organizationId string) ([]repository.Webhook, error) {
+	defer r.lock()()
+	type createdWebhook struct {
+		webhook   repository.Webhook
+		createdAt time.Time
+	}
+	var created []createdWebhook
+	for _, webhook := range r.data.webhooks {
+		if webhook.OrganizationId != organizationId {
+			continue
+		}
+		createdAt, err := parseTime(webhook.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, createdWebhook{webhook: webhook, createdAt: createdAt})
+	}
+	slices.SortFunc(created, func(a, b createdWebhook) int {
+		return cmp.Or(a.createdAt.Compare(b.createdAt), cmp.Compare(a.webhook.Id, b.webhook.Id))
+	})
+	webhooks := make([]repository.Webhook, 0, len(created))
+	for _, c := range created {
+		webhooks = append(webhooks, c.webhook)
+	}
+	return webhooks, nil
+}
+
+// Аналог ON DELETE CASCADE: вместе с подпиской удаляются ее доставки
+func (r *webhookRepository) Delete(webhookId string) error {
+	defer r.lock()()
+	if _, ok := r.data.webhooks[webhookId]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(r.data.webhooks, webhookId)
+	r.data.deliveries = slices.DeleteFunc(r.data.deliveries, func(delivery repository.WebhookDelivery) bool {
+		return delivery.WebhookId == webhookId
+	})
+	return nil
+}
+
+func (r *webhookRepository) CreateDelivery(delivery *repository.WebhookDelivery) error {
+	defer r.lock()()
+	delivery.Id = uuid.NewString()
+	delivery.CreatedAt = now()
+	delivery.UpdatedAt = delivery.CreatedAt
+	delivery.NextAttemptAt = delivery.CreatedAt
+	r.data.deliveries = append(r.data.deliveries, *delivery)
+	return nil
+}
+
+func (r *webhookRepository) GetDelivery(deliveryId string) (*repository.WebhookDelivery, error) {
+	defer r.lock()()
+	for _, delivery := range r.data.deliveries {
+		if delivery.Id == deliveryId {
+			return &delivery, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *webhookRepository) UpdateDelivery(delivery *repository.WebhookDelivery, retryIn time.Duration) error {
+	defer r.lock()()
+	for i := range r.data.deliveries {
+		stored := &r.data.deliveries[i]
+		if stored.Id == delivery.Id {
+			delivery.UpdatedAt = now()
+			delivery.NextAttemptAt = nowAfter(retryIn)
+			stored.Status = delivery.Status
+			stored.Attempts = delivery.Attempts
+			stored.ResponseCode = delivery.ResponseCode
+			stored.LastError = delivery.LastError
+			stored.NextAttemptAt = delivery.NextAttemptAt
+			stored.UpdatedAt = delivery.UpdatedAt
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *webhookRepository) ClaimDue(limit int, lease time.Duration) ([]repository.WebhookDelivery, error) {
+	defer r.lock()()
+	type dueDelivery struct {
+		delivery      *repository.WebhookDelivery
+		nextAttemptAt time.Time
+	}
+	var due []dueDelivery
+	for i := range r.data.deliveries {
+		delivery := &r.data.deliveries[i]
+		if delivery.Status != repository.WebhookDeliveryPending {
+			continue
+		}
+		nextAttemptAt, err := parseTime(delivery.NextAttemptAt)
+		if err != nil {
+			return nil, err
+		}
+		if !nextAttemptAt.After(time.Now()) {
+			due = append(due, dueDelivery{delivery: delivery, nextAttemptAt: nextAttemptAt})
+		}
+	}
+	slices.SortFunc(due, func(a, b dueDelivery) int {
+		return a.nextAttemptAt.Compare(b.nextAttemptAt)
+	})
+
+	deliveries := []repository.WebhookDelivery{}
+	for _, d := range page(due, limit, 0) {
+		d.delivery.NextAttemptAt = nowAfter(lease)
+		deliveries = append(deliveries, *d.delivery)
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) ListDeliveries(webhookId string, limit, offset int) ([]repository.WebhookDelivery, error) {
+	defer r.lock()()
+	deliveries := []repository.WebhookDelivery{}
+	// Доставки хранятся в порядке создания, новые первыми
+	for i := len(r.data.deliveries) - 1; i >= 0; i-- {
+		if r.data.deliveries[i].WebhookId == webhookId {
+			deliveries = append(deliveries, r.data.deliveries[i])
+		}
+	}
+	return page(deliveries, limit, offset), nil
+}
@@ -0,0 +1,257 @@
+package repository
+
+import "time"
+
+type Tender struct {
+	Id             string `db:"id" json:"id"`
+	Name           string `db:"name" json:"name"`
+	Description    string `db:"description" json:"description"`
+	ServiceType    string `db:"service_type" json:"serviceType"`
+	Status         string `db:"status" json:"status"`
+	OrganizationId string `db:"organization_id" json:"organizationId"`
+	Version        int    `db:"version" json:"version"`
+	CreatedAt      string `db:"created_at" json:"createdAt"`
+}
+
+const (
+	ChangeKindEdit         = "edit"
+	ChangeKindRollback     = "rollback"
+	ChangeKindStatusChange = "status_change"
+)
+
+// Поля сортировки списков
+const (
+	SortByName      = "name"
+	SortByCreatedAt = "createdAt"
+)
+
+// Страница списка, записи упорядочены по полю Sort и идентификатору.
+// Если задан After, страница начинается после записи с этим ключом, а Offset не используется
+type Page struct {
+	Limit  int
+	Offset int
+	Sort   string
+	Desc   bool
+	After  *PageKey
+}
+
+// Значение поля сортировки и идентификатор записи, на которой закончилась предыдущая страница
+type PageKey struct {
+	Key string
+	Id  string
+}
+
+// Запись списка, по которой можно начать следующую страницу
+type Pageable interface {
+	PageKey(sort string) PageKey
+}
+
+func (t Tender) PageKey(sort string) PageKey {
+	if sort == SortByCreatedAt {
+		return PageKey{Key: t.CreatedAt, Id: t.Id}
+	}
+	return PageKey{Key: t.Name, Id: t.Id}
+}
+
+func (b Bid) PageKey(sort string) PageKey {
+	if sort == SortByCreatedAt {
+		return PageKey{Key: b.CreatedAt, Id: b.Id}
+	}
+	return PageKey{Key: b.Name, Id: b.Id}
+}
+
+// Условия выборки тендеров, пустые поля выборку не ограничивают
+type TenderFilter struct {
+	ServiceTypes   []string
+	Statuses       []string
+	OrganizationId string
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+	// Подстрока названия без учета регистра
+	Name string
+	// Пользователь видит опубликованные тендеры и тендеры организаций, где у него есть одна из ViewerRoles
+	ViewerUsername string
+	ViewerRoles    []string
+}
+
+// Условия выборки предложений тендера, пустые поля выборку не ограничивают
+type BidFilter struct {
+	Statuses []string
+	// Пользователь видит свои предложения в любом статусе, а опубликованные - если у него есть
+	// одна из ViewerRoles в организации тендера
+	ViewerUsername string
+	ViewerRoles    []string
+}
+
+// Совпадение полнотекстового поиска: ранг и название с описанием, в которых совпадения выделены <b></b>
+type SearchMatch struct {
+	Rank                 float64 `db:"rank"`
+	NameHighlight        string  `db:"name_highlight"`
+	DescriptionHighlight string  `db:"description_highlight"`
+}
+
+type FoundTender struct {
+	Tender
+	SearchMatch
+}
+
+type FoundBid struct {
+	Bid
+	SearchMatch
+}
+
+// Кто, как и почему изменил тендер или предложение, сохраняется в истории версий
+type Change struct {
+	Username string
+	Kind     string
+	Comment  string
+}
+
+// Снимок тендера до правки, сохраненный в tender_version_hist, и сведения о правке
+type TenderVersion struct {
+	TenderId       string `json:"-"`
+	Version        int    `json:"-"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	ServiceType    string `json:"serviceType"`
+	Status         string `json:"status"`
+	OrganizationId string `json:"organizationId"`
+	ChangedAt      string `json:"-"`
+	ChangedBy      string `json:"-"`
+	ChangeKind     string `json:"-"`
+	Comment        string `json:"-"`
+	// Снимок поврежден при записи и не может быть восстановлен, поля заполнены тем, что удалось прочитать
+	Broken bool `json:"-"`
+}
+
+type Bid struct {
+	Id          string  `db:"id" json:"id"`
+	Name        string  `db:"name" json:"name"`
+	Description string  `db:"description" json:"description"`
+	Status      string  `db:"status" json:"status"`
+	TenderId    string  `db:"tender_id" json:"tenderId"`
+	AuthorType  string  `db:"author_type" json:"authorType"`
+	AuthorId    string  `db:"author_id" json:"authorId"`
+	Version     int     `db:"version" json:"version"`
+	CreatedAt   string  `db:"created_at" json:"createdAt"`
+	Decision    *string `db:"decision" json:"decision"`
+}
+
+// Снимок предложения до правки, сохраненный в bid_version_hist, и сведения о правке
+type BidVersion struct {
+	BidId       string `json:"-"`
+	Version     int    `json:"-"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	ChangedAt   string `json:"-"`
+	ChangedBy   string `json:"-"`
+	ChangeKind  string `json:"-"`
+	Comment     string `json:"-"`
+	// Снимок поврежден при записи и не может быть восстановлен, поля заполнены тем, что удалось прочитать
+	Broken bool `json:"-"`
+}
+
+type BidDecision struct {
+	Id       string `db:"id" json:"id"`
+	BidId    string `db:"bid_id" json:"bidId"`
+	Username string `db:"username" json:"username"`
+	Decision string `db:"decision" json:"decision"`
+}
+
+type BidFeedback struct {
+	Id          string `db:"id" json:"id"`
+	BidId       string `db:"bid_id" json:"bidId"`
+	Username    string `db:"username" json:"username"`
+	Description string `db:"description" json:"description"`
+	CreatedAt   string `db:"created_at" json:"createdAt"`
+}
+
+type Organization struct {
+	Id          string `db:"id"`
+	Name        string `db:"name"`
+	Description string `db:"description"`
+	Type        string `db:"type"`
+}
+
+type OrganizationRole struct {
+	Username  string  `db:"username" json:"username"`
+	Role      string  `db:"role" json:"role"`
+	GrantedBy *string `db:"granted_by" json:"grantedBy"`
+	GrantedAt *string `db:"granted_at" json:"grantedAt"`
+}
+
+// Кворум согласований, заданный для организации
+type OrganizationQuorum struct {
+	OrganizationId string `db:"organization_id" json:"organizationId"`
+	Quorum         int    `db:"quorum" json:"quorum"`
+}
+
+// Подписка организации на события. Пустой EventTypes - подписка на все события
+type Webhook struct {
+	Id             string   `db:"id" json:"id"`
+	OrganizationId string   `db:"organization_id" json:"organizationId"`
+	Url            string   `db:"url" json:"url"`
+	Secret         string   `db:"secret" json:"-"`
+	EventTypes     []string `db:"-" json:"eventTypes"`
+	CreatedBy      string   `db:"created_by" json:"createdBy"`
+	CreatedAt      string   `db:"created_at" json:"createdAt"`
+}
+
+// Статусы доставки события подписке
+const (
+	WebhookDeliveryPending   = "Pending"
+	WebhookDeliveryDelivered = "Delivered"
+	WebhookDeliveryFailed    = "Failed"
+)
+
+// Доставка события подписке. Payload - тело запроса в JSON, ResponseCode и LastError - итог последней попытки,
+// NextAttemptAt - время следующей попытки доставки в статусе Pending
+type WebhookDelivery struct {
+	Id            string `db:"id"`
+	WebhookId     string `db:"webhook_id"`
+	EventType     string `db:"event_type"`
+	Payload       string `db:"payload"`
+	Status        string `db:"status"`
+	Attempts      int    `db:"attempts"`
+	ResponseCode  int    `db:"response_code"`
+	LastError     string `db:"last_error"`
+	NextAttemptAt string `db:"next_attempt_at"`
+	CreatedAt     string `db:"created_at"`
+	UpdatedAt     string `db:"updated_at"`
+}
+
+// Событие, записанное в outbox в одной транзакции с изменением. Payload - событие в JSON
+type OutboxEvent struct {
+	Id        int64  `db:"id"`
+	EventType string `db:"event_type"`
+	TenderId  string `db:"tender_id"`
+	Payload   string `db:"payload"`
+	CreatedAt string `db:"created_at"`
+}
+
+// Запись журнала аудита. Before и After - снимки сущности в JSON до и после изменения,
+// пустые, если сущности не было или она удалена
+type AuditEntry struct {
+	Id             int64  `db:"id"`
+	Actor          string `db:"actor"`
+	Action         string `db:"action"`
+	EntityType     string `db:"entity_type"`
+	EntityId       string `db:"entity_id"`
+	OrganizationId string `db:"organization_id"`
+	Before         string `db:"before"`
+	After          string `db:"after"`
+	RequestId      string `db:"request_id"`
+	Ip             string `db:"ip"`
+	CreatedAt      string `db:"created_at"`
+}
+
+// Условия выборки журнала аудита, пустые поля кроме OrganizationId выборку не ограничивают
+type AuditFilter struct {
+	OrganizationId string
+	EntityType     string
+	EntityId       string
+	Actor          string
+	From           *time.Time
+	To             *time.Time
+}
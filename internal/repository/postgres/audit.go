@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"avitoTask/internal/repository"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type auditRepository struct {
+	db sqlx.Ext
+}
+
+func (r *auditRepository) Create(entry *repository.AuditEntry) error {
+	err := r.db.QueryRowx(`INSERT INTO audit_log
+									(actor,
+									action,
+									entity_type,
+									entity_id,
+									organization_id,
+									before,
+									after,
+									request_id,
+									ip)
+						VALUES     ($1,
+									$2,
+									$3,
+									$4,
+									$5,
+									NULLIF($6, '')::jsonb,
+									NULLIF($7, '')::jsonb,
+									$8,
+									$9)
+						RETURNING id, created_at`, entry.Actor, entry.Action, entry.EntityType, entry.EntityId,
+		entry.OrganizationId, entry.Before, entry.After, entry.RequestId, entry.Ip).
+		Scan(&entry.Id, &entry.CreatedAt)
+	return mapError(err)
+}
+
+func (r *auditRepository) List(filter repository.AuditFilter, limit, offset int) ([]repository.AuditEntry, error) {
+	entries := []repository.AuditEntry{}
+	err := sqlx.Select(r.db, &entries, `SELECT id,
+									actor,
+									action,
+									entity_type,
+									entity_id,
+									organization_id,
+									COALESCE(before::text, '') AS before,
+									COALESCE(after::text, '') AS after,
+									request_id,
+									ip,
+									created_at
+								FROM audit_log
+								WHERE organization_id = $1
+									AND ($2 = '' OR entity_type = $2)
+									AND ($3 = '' OR entity_id = $3)
+									AND ($4 = '' OR actor = $4)
+									AND ($5::timestamp IS NULL OR created_at >= $5)
+									AND ($6::timestamp IS NULL OR created_at <= $6)
+								ORDER BY created_at DESC, id DESC
+								LIMIT $7 OFFSET $8`, filter.OrganizationId, filter.EntityType, filter.EntityId,
+		filter.Actor, filter.From, filter.To, limit, offset)
+	return entries, err
+}
@@ -0,0 +1,307 @@
+package postgres
+
+import (
+	"encoding/json"
+
+	"avitoTask/internal/repository"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type bidRepository struct {
+	db sqlx.Ext
+}
+
+const bidColumns = `id,
+					name,
+					description,
+					status,
+					tender_id,
+					author_type,
+					author_id,
+					version,
+					created_at,
+					decision`
+
+// Предложения, автором которых является пользователь $1 лично или организация, в которой у него есть роль
+const bidAuthorCondition = `(b.author_type = 'User' AND EXISTS(SELECT 1
+														FROM employee emp
+														WHERE emp.id = b.author_id AND emp.username = $1)
+					OR b.author_type = 'Organization'
+						AND EXISTS(SELECT 1
+									FROM organization_member_role org_mr
+										JOIN employee emp ON emp.id = org_mr.user_id AND emp.username = $1
+									WHERE org_mr.organization_id = b.author_id))`
+
+// Предложения, автором которых является пользователь $1 лично или организация, в которой у него есть одна из ролей $2
+const bidMemberCondition = `(b.author_type = 'User' AND EXISTS(SELECT 1
+														FROM employee emp
+														WHERE emp.id = b.author_id AND emp.username = $1)
+					OR b.author_type = 'Organization'
+						AND EXISTS(SELECT 1
+									FROM organization_member_role org_mr
+										JOIN employee emp ON emp.id = org_mr.user_id AND emp.username = $1
+									WHERE org_mr.organization_id = b.author_id AND org_mr.role = ANY ($2)))`
+
+func (r *bidRepository) Exists(bidId string) error {
+	var bidExists bool
+	return sqlx.Get(r.db, &bidExists, `SELECT TRUE
+								FROM   bid
+								WHERE  id = $1`, bidId)
+}
+
+func (r *bidRepository) Get(bidId string) (*repository.Bid, error) {
+	var bid repository.Bid
+	err := sqlx.Get(r.db, &bid, `SELECT `+bidColumns+`
+							FROM bid WHERE id = $1`, bidId)
+	if err != nil {
+		return nil, err
+	}
+	return &bid, nil
+}
+
+// Предложения тендера $2 со статусами $3 (все при $4 = 0), которые видит пользователь $1 с ролями $5
+const bidTenderCondition = `b.tender_id = $2
+				AND (b.status = ANY ( $3 ) OR $4 = 0)
+				AND (` + bidAuthorCondition + `
+					OR b.status = 'Published'
+						AND EXISTS(SELECT 1
+									FROM tender t
+										JOIN organization_member_role org_mr ON org_mr.organization_id = t.organization_id
+										JOIN employee emp ON emp.id = org_mr.user_id
+									WHERE t.id = b.tender_id AND emp.username = $1 AND org_mr.role = ANY ( $5 )))`
+
+func (r *bidRepository) ListByTender(tenderId string, filter repository.BidFilter, page repository.Page) ([]repository.Bid, error) {
+	query, args := pageQuery(`SELECT `+bidColumns+`
+				FROM   bid b
+				WHERE `+bidTenderCondition, "b", page, []any{filter.ViewerUsername, tenderId,
+		pq.Array(filter.Statuses), len(filter.Statuses), pq.Array(filter.ViewerRoles)})
+	bids := []repository.Bid{}
+	err := sqlx.Select(r.db, &bids, query, args...)
+	return bids, err
+}
+
+func (r *bidRepository) CountByTender(tenderId string, filter repository.BidFilter) (int, error) {
+	var bidsCnt int
+	err := sqlx.Get(r.db, &bidsCnt, `SELECT COUNT(*)
+				FROM   bid b
+				WHERE `+bidTenderCondition, filter.ViewerUsername, tenderId,
+		pq.Array(filter.Statuses), len(filter.Statuses), pq.Array(filter.ViewerRoles))
+	return bidsCnt, err
+}
+
+func (r *bidRepository) CountByStatus(tenderId string, filter repository.BidFilter) (map[string]int, error) {
+	var statusCounts []struct {
+		Status string `db:"status"`
+		Count  int    `db:"count"`
+	}
+	err := sqlx.Select(r.db, &statusCounts, `SELECT b.status, COUNT(*) AS count
+							FROM bid b
+							WHERE b.tender_id = $5
+								AND `+bidViewCondition+`
+							GROUP BY b.status`, filter.ViewerUsername, pq.Array(filter.ViewerRoles),
+		pq.Array(filter.Statuses), len(filter.Statuses), tenderId)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for _, statusCount := range statusCounts {
+		counts[statusCount.Status] = statusCount.Count
+	}
+	return counts, nil
+}
+
+func (r *bidRepository) GetApproved(tenderId string) (*repository.Bid, error) {
+	var bid repository.Bid
+	err := sqlx.Get(r.db, &bid, `SELECT `+bidColumns+`
+							FROM bid
+							WHERE tender_id = $1 AND decision = 'Approved'
+							LIMIT 1`, tenderId)
+	if err != nil {
+		return nil, err
+	}
+	return &bid, nil
+}
+
+func (r *bidRepository) ListByMember(username string, roles []string, page repository.Page) ([]repository.Bid, error) {
+	query, args := pageQuery(`SELECT `+bidColumns+`
+				FROM bid b
+				WHERE `+bidMemberCondition, "b", page, []any{username, pq.Array(roles)})
+	bids := []repository.Bid{}
+	err := sqlx.Select(r.db, &bids, query, args...)
+	return bids, err
+}
+
+func (r *bidRepository) CountByMember(username string, roles []string) (int, error) {
+	var bidsCnt int
+	err := sqlx.Get(r.db, &bidsCnt, `SELECT COUNT(*)
+				FROM bid b
+				WHERE `+bidMemberCondition, username, pq.Array(roles))
+	return bidsCnt, err
+}
+
+// Предложения со статусами $3 (все при $4 = 0), которые видит пользователь $1: свои в любом статусе,
+// а опубликованные - если у него есть одна из ролей $2 в организации тендера
+const bidViewCondition = `(b.status = ANY ( $3 ) OR $4 = 0)
+				AND (` + bidAuthorCondition + `
+					OR b.status = 'Published'
+						AND EXISTS(SELECT 1
+									FROM tender t
+										JOIN organization_member_role org_mr ON org_mr.organization_id = t.organization_id
+										JOIN employee emp ON emp.id = org_mr.user_id
+									WHERE t.id = b.tender_id AND emp.username = $1 AND org_mr.role = ANY ( $2 )))`
+
+func (r *bidRepository) Search(query string, filter repository.BidFilter, limit, offset int) ([]repository.FoundBid, error) {
+	bids := []repository.FoundBid{}
+	err := sqlx.Select(r.db, &bids, `SELECT `+bidColumns+`,
+					`+searchColumns("b")+`
+				FROM   bid b,
+					`+searchQuery(5)+`
+				WHERE  b.search_vector @@ q.query
+					AND `+bidViewCondition+`
+				ORDER BY rank DESC, b.id
+				LIMIT $6 OFFSET $7`, filter.ViewerUsername, pq.Array(filter.ViewerRoles),
+		pq.Array(filter.Statuses), len(filter.Statuses), query, limit, offset)
+	return bids, err
+}
+
+func (r *bidRepository) Create(bid *repository.Bid) error {
+	query := `INSERT INTO bid
+							(name,
+							description,
+							status,
+							tender_id,
+							author_type,
+							author_id,
+							version,
+							created_at)
+				VALUES     ($1,
+							$2,
+							$3,
+							$4,
+							$5,
+							$6,
+							$7,
+							$8)
+						RETURNING id`
+	err := r.db.QueryRowx(query, bid.Name, bid.Description, bid.Status,
+		bid.TenderId, bid.AuthorType, bid.AuthorId,
+		bid.Version, bid.CreatedAt).Scan(&bid.Id)
+	return mapError(err)
+}
+
+func (r *bidRepository) UpdateStatus(bidId, status string, change repository.Change) error {
+	query := `UPDATE bid
+				SET    status = $1,
+						changed_by = $3,
+						change_kind = $4,
+						change_comment = NULLIF($5, '')
+				WHERE  id = $2`
+	_, err := r.db.Exec(query, status, bidId, change.Username, change.Kind, change.Comment)
+	return mapError(err)
+}
+
+func (r *bidRepository) Update(bid *repository.Bid, change repository.Change) error {
+	query := `UPDATE bid
+				SET    name = $1,
+						description = $2,
+						changed_by = $4,
+						change_kind = $5,
+						change_comment = NULLIF($6, '')
+				WHERE  id = $3`
+	_, err := r.db.Exec(query, bid.Name, bid.Description, bid.Id,
+		change.Username, change.Kind, change.Comment)
+	return mapError(err)
+}
+
+func (r *bidRepository) SetDecision(bidId, decision string) error {
+	_, err := r.db.Exec("UPDATE bid SET decision = $1 WHERE id = $2", decision, bidId)
+	return mapError(err)
+}
+
+func (r *bidRepository) GetVersion(bidId string, version int) (*repository.BidVersion, error) {
+	var hist versionHist
+	err := sqlx.Get(r.db, &hist, `SELECT `+versionHistColumns+`
+							FROM bid_version_hist
+							WHERE bid_id = $1 AND version = $2`, bidId, version)
+	if err != nil {
+		return nil, err
+	}
+	return newBidVersion(bidId, &hist)
+}
+
+func (r *bidRepository) ListVersions(bidId string) ([]repository.BidVersion, error) {
+	hists := []versionHist{}
+	err := sqlx.Select(r.db, &hists, `SELECT `+versionHistColumns+`
+							FROM bid_version_hist
+							WHERE bid_id = $1
+							ORDER BY version`, bidId)
+	if err != nil {
+		return nil, err
+	}
+	bidVersions := make([]repository.BidVersion, 0, len(hists))
+	for i := range hists {
+		bidVersion, err := newBidVersion(bidId, &hists[i])
+		if err != nil {
+			return nil, err
+		}
+		bidVersions = append(bidVersions, *bidVersion)
+	}
+	return bidVersions, nil
+}
+
+func newBidVersion(bidId string, hist *versionHist) (*repository.BidVersion, error) {
+	bidVersion := repository.BidVersion{
+		BidId:      bidId,
+		Version:    hist.Version,
+		ChangedAt:  hist.ChangedAt,
+		ChangedBy:  hist.ChangedBy,
+		ChangeKind: hist.ChangeKind,
+		Comment:    hist.Comment,
+		Broken:     hist.Broken,
+	}
+	// Из поврежденного снимка берутся поля, которые удалось прочитать
+	err := json.Unmarshal([]byte(hist.Params), &bidVersion)
+	if err != nil && !hist.Broken {
+		return nil, err
+	}
+	return &bidVersion, nil
+}
+
+func (r *bidRepository) CreateFeedback(feedback *repository.BidFeedback) error {
+	err := r.db.QueryRowx(`INSERT INTO bid_feedback
+								(bid_id,
+								username,
+								description)
+					VALUES     ($1,
+								$2,
+								$3)
+					RETURNING id, created_at`, feedback.BidId, feedback.Username, feedback.Description).
+		Scan(&feedback.Id, &feedback.CreatedAt)
+	return mapError(err)
+}
+
+func (r *bidRepository) ListAuthorFeedback(authorUsername string, limit, offset int) ([]repository.BidFeedback, error) {
+	query := `SELECT bf.id,
+					bf.bid_id,
+					bf.username,
+					bf.description,
+					bf.created_at
+				FROM bid_feedback bf
+					JOIN bid b ON b.id = bf.bid_id
+				WHERE ` + bidAuthorCondition + `
+				ORDER BY bf.created_at DESC
+				LIMIT $2 OFFSET $3`
+	feedback := []repository.BidFeedback{}
+	err := sqlx.Select(r.db, &feedback, query, authorUsername, limit, offset)
+	return feedback, err
+}
+
+func (r *bidRepository) ExistsByAuthor(tenderId, authorUsername string) error {
+	var bidExists bool
+	return sqlx.Get(r.db, &bidExists, `SELECT TRUE
+								FROM bid b
+								WHERE b.tender_id = $2 AND `+bidAuthorCondition+`
+								LIMIT 1`, authorUsername, tenderId)
+}
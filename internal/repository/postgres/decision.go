@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"avitoTask/internal/repository"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type decisionRepository struct {
+	db sqlx.Ext
+}
+
+func (r *decisionRepository) Create(decision *repository.BidDecision) error {
+	err := r.db.QueryRowx(`INSERT INTO bid_decision
+									(bid_id,
+									username,
+									decision)
+						VALUES     ($1,
+									$2,
+									$3)
+						RETURNING id`, decision.BidId,
+		decision.Username, decision.Decision).Scan(&decision.Id)
+	return mapError(err)
+}
+
+func (r *decisionRepository) CountByUser(bidId, username string) (int, error) {
+	var decisionCnt int
+	err := sqlx.Get(r.db, &decisionCnt, `SELECT COUNT(*)
+							FROM bid_decision
+							WHERE bid_id = $1 AND username=$2`,
+		bidId, username)
+	return decisionCnt, err
+}
+
+func (r *decisionRepository) ListByBid(bidId string) ([]repository.BidDecision, error) {
+	decisions := []repository.BidDecision{}
+	err := sqlx.Select(r.db, &decisions, `SELECT id, bid_id, username, decision
+							FROM bid_decision
+							WHERE bid_id = $1
+							ORDER BY id`,
+		bidId)
+	return decisions, err
+}
+
+func (r *decisionRepository) CountApproved(bidId string) (int, error) {
+	var decisionCnt int
+	err := sqlx.Get(r.db, &decisionCnt, `SELECT COUNT(*)
+							FROM bid_decision
+							WHERE bid_id = $1 AND decision = 'Approved'`,
+		bidId)
+	return decisionCnt, err
+}
@@ -0,0 +1,24 @@
+package postgres
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+type employeeRepository struct {
+	db sqlx.Ext
+}
+
+func (r *employeeRepository) Exists(username string) error {
+	var userExists bool
+	return sqlx.Get(r.db, &userExists, `SELECT TRUE
+								FROM   employee
+								WHERE  username = $1`, username)
+}
+
+func (r *employeeRepository) GetId(username string) (string, error) {
+	var id string
+	err := sqlx.Get(r.db, &id, `SELECT id
+								FROM   employee
+								WHERE  username = $1`, username)
+	return id, err
+}
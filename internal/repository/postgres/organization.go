@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"avitoTask/internal/repository"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type organizationRepository struct {
+	db sqlx.Ext
+}
+
+func (r *organizationRepository) Exists(organizationId string) error {
+	var organizationExists bool
+	return sqlx.Get(r.db, &organizationExists, `SELECT TRUE
+								FROM   organization
+								WHERE  id = $1`, organizationId)
+}
+
+func (r *organizationRepository) Get(organizationId string) (*repository.Organization, error) {
+	var organization repository.Organization
+	err := sqlx.Get(r.db, &organization, `SELECT id,
+									name,
+									COALESCE(description, '') AS description,
+									COALESCE(type::text, '') AS type
+								FROM organization
+								WHERE id = $1`, organizationId)
+	if err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
+func (r *organizationRepository) GetMemberRoles(organizationId, username string) ([]string, error) {
+	roles := []string{}
+	err := sqlx.Select(r.db, &roles, `SELECT org_mr.role
+								FROM organization_member_role org_mr
+									JOIN employee emp ON emp.id = org_mr.user_id
+								WHERE org_mr.organization_id = $1 AND emp.username = $2`, organizationId, username)
+	return roles, err
+}
+
+func (r *organizationRepository) CountMembersWithRoles(organizationId string, roles []string) (int, error) {
+	var membersCnt int
+	err := sqlx.Get(r.db, &membersCnt, `SELECT COUNT(DISTINCT org_mr.user_id)
+								FROM organization_member_role org_mr
+								WHERE org_mr.organization_id = $1 AND org_mr.role = ANY ($2)`,
+		organizationId, pq.Array(roles))
+	return membersCnt, err
+}
+
+func (r *organizationRepository) GetQuorum(organizationId string) (int, error) {
+	var quorum int
+	err := sqlx.Get(r.db, &quorum, `SELECT quorum
+								FROM organization_quorum
+								WHERE organization_id = $1`, organizationId)
+	return quorum, err
+}
+
+func (r *organizationRepository) SetQuorum(organizationId string, quorum int) error {
+	_, err := r.db.Exec(`INSERT INTO organization_quorum
+								(organization_id,
+								quorum)
+					VALUES ($1, $2)
+					ON CONFLICT (organization_id) DO UPDATE SET quorum = EXCLUDED.quorum`,
+		organizationId, quorum)
+	return mapError(err)
+}
+
+func (r *organizationRepository) ListRoles(organizationId string) ([]repository.OrganizationRole, error) {
+	query := `SELECT emp.username,
+					org_mr.role,
+					org_er.granted_by,
+					org_er.granted_at
+				FROM organization_member_role org_mr
+					JOIN employee emp ON emp.id = org_mr.user_id
+					LEFT JOIN organization_employee_role org_er ON org_er.organization_id = org_mr.organization_id
+						AND org_er.user_id = org_mr.user_id
+						AND org_er.role = org_mr.role
+				WHERE org_mr.organization_id = $1
+				ORDER BY emp.username, org_mr.role`
+	roles := []repository.OrganizationRole{}
+	err := sqlx.Select(r.db, &roles, query, organizationId)
+	return roles, err
+}
+
+func (r *organizationRepository) GrantRole(organizationId, username, role, grantedBy string) (*repository.OrganizationRole, error) {
+	_, err := r.db.Exec(`INSERT INTO organization_employee_role
+								(organization_id,
+								user_id,
+								role,
+								granted_by)
+					SELECT $1,
+							emp.id,
+							$3,
+							$4
+					FROM employee emp
+					WHERE emp.username = $2
+					ON CONFLICT (organization_id, user_id, role) DO NOTHING`,
+		organizationId, username, role, grantedBy)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	var grantedRole repository.OrganizationRole
+	err = sqlx.Get(r.db, &grantedRole, `SELECT emp.username,
+										org_er.role,
+										org_er.granted_by,
+										org_er.granted_at
+								FROM organization_employee_role org_er
+									JOIN employee emp ON emp.id = org_er.user_id
+								WHERE org_er.organization_id = $1 AND emp.username = $2 AND org_er.role = $3`,
+		organizationId, username, role)
+	if err != nil {
+		return nil, err
+	}
+	return &grantedRole, nil
+}
+
+func (r *organizationRepository) RevokeRole(organizationId, username, role string) error {
+	result, err := r.db.Exec(`DELETE FROM organization_employee_role org_er
+							USING employee emp
+							WHERE emp.id = org_er.user_id
+								AND org_er.organization_id = $1
+								AND emp.username = $2
+								AND org_er.role = $3`,
+		organizationId, username, role)
+	if err != nil {
+		return mapError(err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
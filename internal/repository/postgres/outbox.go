@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"time"
+
+	"avitoTask/internal/repository"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type outboxRepository struct {
+	db sqlx.Ext
+}
+
+func (r *outboxRepository) Create(event *repository.OutboxEvent) error {
+	err := r.db.QueryRowx(`INSERT INTO outbox_event
+									(event_type,
+									tender_id,
+									payload)
+						VALUES     ($1,
+									$2,
+									$3)
+						RETURNING id, created_at`, event.EventType, event.TenderId, event.Payload).
+		Scan(&event.Id, &event.CreatedAt)
+	return mapError(err)
+}
+
+func (r *outboxRepository) ListPending(sink string, limit int) ([]repository.OutboxEvent, error) {
+	outboxEvents := []repository.OutboxEvent{}
+	err := sqlx.Select(r.db, &outboxEvents, `SELECT e.id,
+									e.event_type,
+									e.tender_id,
+									e.payload,
+									e.created_at
+								FROM outbox_event e
+								WHERE NOT EXISTS(SELECT 1
+												FROM outbox_event_sink es
+												WHERE es.sink = $1 AND es.event_id = e.id)
+								ORDER BY e.id
+								LIMIT $2
+								FOR UPDATE SKIP LOCKED`, sink, limit)
+	return outboxEvents, err
+}
+
+func (r *outboxRepository) MarkProcessed(sink string, eventId int64) error {
+	_, err := r.db.Exec(`INSERT INTO outbox_event_sink
+									(sink,
+									event_id)
+						VALUES     ($1,
+									$2)
+						ON CONFLICT DO NOTHING`, sink, eventId)
+	return mapError(err)
+}
+
+func (r *outboxRepository) LastId() (int64, error) {
+	var id int64
+	err := r.db.QueryRowx(`SELECT COALESCE(MAX(id), 0) FROM outbox_event`).Scan(&id)
+	return id, err
+}
+
+// Отметки удаляются каскадно
+func (r *outboxRepository) DeleteProcessed(sinks []string, olderThan time.Duration) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM outbox_event e
+						WHERE e.created_at < CURRENT_TIMESTAMP - $2 * INTERVAL '1 millisecond'
+							AND (SELECT COUNT(*)
+								FROM outbox_event_sink es
+								WHERE es.event_id = e.id AND es.sink = ANY($1)) = CARDINALITY($1::varchar[])`,
+		pq.Array(sinks), olderThan.Milliseconds())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
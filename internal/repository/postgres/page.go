@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"fmt"
+
+	"avitoTask/internal/repository"
+)
+
+// Столбцы сортировки списков и тип, к которому приводится ключ начала страницы
+var sortColumns = map[string]struct {
+	column  string
+	keyType string
+}{
+	repository.SortByName:      {column: "name", keyType: "text"},
+	repository.SortByCreatedAt: {column: "created_at", keyType: "timestamp"},
+}
+
+// Дополняет запрос, заканчивающийся условием WHERE, условием начала страницы, сортировкой и LIMIT/OFFSET.
+// alias - псевдоним таблицы, args - параметры запроса, новые параметры нумеруются после них
+func pageQuery(query, alias string, page repository.Page, args []any) (string, []any) {
+	sort, ok := sortColumns[page.Sort]
+	if !ok {
+		sort = sortColumns[repository.SortByName]
+	}
+	column := alias + "." + sort.column
+	direction, compare := "ASC", ">"
+	if page.Desc {
+		direction, compare = "DESC", "<"
+	}
+
+	offset := page.Offset
+	if page.After != nil {
+		query += fmt.Sprintf(`
+				AND (%s, %s.id) %s ($%d::%s, $%d::uuid)`, column, alias, compare, len(args)+1, sort.keyType, len(args)+2)
+		args = append(args, page.After.Key, page.After.Id)
+		offset = 0
+	}
+	query += fmt.Sprintf(`
+				ORDER BY %s %s, %s.id %s
+				LIMIT $%d OFFSET $%d`, column, direction, alias, direction, len(args)+1, len(args)+2)
+	return query, append(args, page.Limit, offset)
+}
@@ -0,0 +1,27 @@
+package postgres
+
+import "fmt"
+
+// Параметры ts_headline: совпадения выделяются тегами <b></b>
+const headlineOptions = `StartSel=<b>, StopSel=</b>`
+
+// Подзапрос с поисковым запросом из параметра $argNum в русской и английской конфигурациях
+func searchQuery(argNum int) string {
+	return fmt.Sprintf(`(SELECT websearch_to_tsquery('russian', $%d) || websearch_to_tsquery('english', $%d) AS query) q`,
+		argNum, argNum)
+}
+
+// Выражение, экранирующее текст expr как HTML (как html.EscapeString). Текст экранируется до ts_headline,
+// чтобы в ответе тегами были только выделения совпадений
+func escapeHtml(expr string) string {
+	return fmt.Sprintf(`replace(replace(replace(replace(replace(%s, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;')`,
+		expr)
+}
+
+// Ранг и выделенные совпадения для таблицы с псевдонимом alias
+func searchColumns(alias string) string {
+	return fmt.Sprintf(`ts_rank(%[1]s.search_vector, q.query) AS rank,
+					ts_headline('russian', %[2]s, q.query, '%[4]s, HighlightAll=true') AS name_highlight,
+					ts_headline('russian', %[3]s, q.query, '%[4]s') AS description_highlight`,
+		alias, escapeHtml(alias+".name"), escapeHtml(fmt.Sprintf("COALESCE(%s.description, '')", alias)), headlineOptions)
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"avitoTask/internal/repository"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Код ошибки Postgres, которым триггеры сообщают о нарушении бизнес-правил
+const checkViolationCode pq.ErrorCode = "23514"
+
+// Строка tender_version_hist или bid_version_hist, params - снимок полей до правки
+type versionHist struct {
+	Version    int    `db:"version"`
+	Params     string `db:"params"`
+	ChangedAt  string `db:"changed_at"`
+	ChangedBy  string `db:"changed_by"`
+	ChangeKind string `db:"change_kind"`
+	Comment    string `db:"comment"`
+	Broken     bool   `db:"broken"`
+}
+
+const versionHistColumns = `version,
+					params,
+					changed_at,
+					COALESCE(changed_by, '') AS changed_by,
+					COALESCE(change_kind::text, '') AS change_kind,
+					COALESCE(comment, '') AS comment,
+					broken`
+
+func NewStore(db *sqlx.DB) *repository.Store {
+	store := newStore(db)
+	store.Transactor = &transactor{db: db}
+	return store
+}
+
+func newStore(ext sqlx.Ext) *repository.Store {
+	return &repository.Store{
+		Tenders:       &tenderRepository{db: ext},
+		Bids:          &bidRepository{db: ext},
+		Decisions:     &decisionRepository{db: ext},
+		Employees:     &employeeRepository{db: ext},
+		Organizations: &organizationRepository{db: ext},
+		Webhooks:      &webhookRepository{db: ext},
+		Outbox:        &outboxRepository{db: ext},
+		Audit:         &auditRepository{db: ext},
+	}
+}
+
+type transactor struct {
+	db *sqlx.DB
+}
+
+func (t *transactor) InTx(fn func(tx *repository.Store) error) error {
+	tx, err := t.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	store := newStore(tx)
+	store.Transactor = &nestedTransactor{store: store}
+	err = fn(store)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Внутри транзакции вложенные вызовы InTx выполняются в ней же
+type nestedTransactor struct {
+	store *repository.Store
+}
+
+func (t *nestedTransactor) InTx(fn func(tx *repository.Store) error) error {
+	return fn(t.store)
+}
+
+func mapError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == checkViolationCode {
+		return &repository.ConflictError{Reason: pqErr.Message}
+	}
+	return err
+}
@@ -0,0 +1,83 @@
+package postgres_test
+
+import (
+	"os"
+	"testing"
+
+	"avitoTask/internal/repository"
+	"avitoTask/internal/repository/memory"
+	postgresRepository "avitoTask/internal/repository/postgres"
+	"avitoTask/internal/repository/repositorytest"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+// База для проверок задается строкой подключения, в ней должны быть таблицы сотрудников и организаций
+const dsnEnv = "TEST_POSTGRES_DSN"
+
+func TestContract(t *testing.T) {
+	dsn := os.Getenv(dsnEnv)
+	if dsn == "" {
+		t.Skipf("%s не задана", dsnEnv)
+	}
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	migrateUp(t, db)
+
+	repositorytest.Run(t, func(t *testing.T, seed *memory.Seed) *repository.Store {
+		insertSeed(t, db, seed)
+		return postgresRepository.NewStore(db)
+	})
+}
+
+func migrateUp(t *testing.T, db *sqlx.DB) {
+	driver, err := postgres.WithInstance(db.DB, &postgres.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrate.NewWithDatabaseInstance("file://../../../migrations", "postgres", driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = m.Up()
+	if err != nil && err != migrate.ErrNoChange {
+		t.Fatal(err)
+	}
+}
+
+func insertSeed(t *testing.T, db *sqlx.DB, seed *memory.Seed) {
+	for _, employee := range seed.Employees {
+		_, err := db.Exec(`INSERT INTO employee (id, username) VALUES ($1, $2)`, employee.Id, employee.Username)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, organization := range seed.Organizations {
+		_, err := db.Exec(`INSERT INTO organization (id, name, description, type) VALUES ($1, $2, $3, $4)`,
+			organization.Id, organization.Name, organization.Description, organization.Type)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, responsible := range seed.OrganizationResponsibles {
+		_, err := db.Exec(`INSERT INTO organization_responsible (organization_id, user_id) VALUES ($1, $2)`,
+			responsible.OrganizationId, responsible.UserId)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, quorum := range seed.OrganizationQuorums {
+		_, err := db.Exec(`INSERT INTO organization_quorum (organization_id, quorum) VALUES ($1, $2)`,
+			quorum.OrganizationId, quorum.Quorum)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
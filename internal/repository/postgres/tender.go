@@ -0,0 +1,213 @@
+package postgres
+
+import (
+	"encoding/json"
+
+	"avitoTask/internal/repository"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type tenderRepository struct {
+	db sqlx.Ext
+}
+
+const tenderColumns = `id,
+					name,
+					COALESCE(description,'') as description,
+					status,
+					service_type,
+					organization_id,
+					version,
+					created_at`
+
+func (r *tenderRepository) Exists(tenderId string) error {
+	var tenderExists bool
+	return sqlx.Get(r.db, &tenderExists, `SELECT TRUE
+								FROM   tender
+								WHERE  id = $1`, tenderId)
+}
+
+func (r *tenderRepository) Get(tenderId string) (*repository.Tender, error) {
+	var tender repository.Tender
+	err := sqlx.Get(r.db, &tender, `SELECT `+tenderColumns+`
+							FROM tender WHERE id = $1`, tenderId)
+	if err != nil {
+		return nil, err
+	}
+	return &tender, nil
+}
+
+// Тендеры по фильтру $1-$8, которые видит пользователь $9 с ролями $10
+const tenderFilterCondition = `(t.service_type = ANY ( $1 ) OR $2 = 0)
+				AND (t.status = ANY ( $3 ) OR $4 = 0)
+				AND ($5 = '' OR t.organization_id::text = $5)
+				AND ($6::timestamp IS NULL OR t.created_at >= $6)
+				AND ($7::timestamp IS NULL OR t.created_at <= $7)
+				AND strpos(lower(t.name), lower($8)) > 0
+				AND (t.status = 'Published'
+					OR EXISTS(SELECT 1
+							FROM organization_member_role org_mr
+								JOIN employee emp ON emp.id = org_mr.user_id
+							WHERE org_mr.organization_id = t.organization_id
+								AND emp.username = $9
+								AND org_mr.role = ANY ( $10 )))`
+
+func tenderFilterArgs(filter repository.TenderFilter) []any {
+	return []any{
+		pq.Array(filter.ServiceTypes), len(filter.ServiceTypes),
+		pq.Array(filter.Statuses), len(filter.Statuses),
+		filter.OrganizationId, filter.CreatedFrom, filter.CreatedTo, filter.Name,
+		filter.ViewerUsername, pq.Array(filter.ViewerRoles),
+	}
+}
+
+// Тендеры организаций, в которых у пользователя $1 есть одна из ролей $2
+const tenderMemberCondition = `EXISTS(SELECT 1
+							FROM organization_member_role org_mr
+								JOIN employee e ON org_mr.user_id = e.id
+							WHERE org_mr.organization_id = t.organization_id AND e.username = $1
+								AND org_mr.role = ANY ($2))`
+
+func (r *tenderRepository) List(filter repository.TenderFilter, page repository.Page) ([]repository.Tender, error) {
+	query, args := pageQuery(`SELECT `+tenderColumns+`
+				FROM   tender t
+				WHERE  `+tenderFilterCondition, "t", page, tenderFilterArgs(filter))
+	tenders := []repository.Tender{}
+	err := sqlx.Select(r.db, &tenders, query, args...)
+	return tenders, err
+}
+
+func (r *tenderRepository) Count(filter repository.TenderFilter) (int, error) {
+	var tendersCnt int
+	err := sqlx.Get(r.db, &tendersCnt, `SELECT COUNT(*)
+				FROM   tender t
+				WHERE  `+tenderFilterCondition, tenderFilterArgs(filter)...)
+	return tendersCnt, err
+}
+
+func (r *tenderRepository) ListByMember(username string, roles []string, page repository.Page) ([]repository.Tender, error) {
+	query, args := pageQuery(`SELECT `+tenderColumns+`
+				FROM tender t
+				WHERE `+tenderMemberCondition, "t", page, []any{username, pq.Array(roles)})
+	tenders := []repository.Tender{}
+	err := sqlx.Select(r.db, &tenders, query, args...)
+	return tenders, err
+}
+
+func (r *tenderRepository) CountByMember(username string, roles []string) (int, error) {
+	var tendersCnt int
+	err := sqlx.Get(r.db, &tendersCnt, `SELECT COUNT(*)
+				FROM tender t
+				WHERE `+tenderMemberCondition, username, pq.Array(roles))
+	return tendersCnt, err
+}
+
+func (r *tenderRepository) Search(query string, filter repository.TenderFilter, limit, offset int) ([]repository.FoundTender, error) {
+	tenders := []repository.FoundTender{}
+	err := sqlx.Select(r.db, &tenders, `SELECT `+tenderColumns+`,
+					`+searchColumns("t")+`
+				FROM   tender t,
+					`+searchQuery(11)+`
+				WHERE  t.search_vector @@ q.query
+					AND `+tenderFilterCondition+`
+				ORDER BY rank DESC, t.id
+				LIMIT $12 OFFSET $13`, append(tenderFilterArgs(filter), query, limit, offset)...)
+	return tenders, err
+}
+
+func (r *tenderRepository) Create(tender *repository.Tender) error {
+	err := r.db.QueryRowx(`INSERT INTO tender
+									(name,
+									description,
+									service_type,
+									status,
+									organization_id,
+									version,
+									created_at)
+						VALUES     ($1,
+									$2,
+									$3,
+									$4,
+									$5,
+									$6,
+									$7)
+						RETURNING id`, tender.Name, tender.Description, tender.ServiceType, tender.Status, tender.OrganizationId,
+		tender.Version, tender.CreatedAt).Scan(&tender.Id)
+	return mapError(err)
+}
+
+func (r *tenderRepository) UpdateStatus(tenderId, status string, change repository.Change) error {
+	query := `UPDATE tender
+				SET    status = $1,
+						changed_by = $3,
+						change_kind = $4,
+						change_comment = NULLIF($5, '')
+				WHERE  id = $2`
+	_, err := r.db.Exec(query, status, tenderId, change.Username, change.Kind, change.Comment)
+	return mapError(err)
+}
+
+func (r *tenderRepository) Update(tender *repository.Tender, change repository.Change) error {
+	query := `UPDATE tender
+				SET    name = $1,
+						description = $2,
+						service_type = $3,
+						changed_by = $5,
+						change_kind = $6,
+						change_comment = NULLIF($7, '')
+				WHERE  id = $4`
+	_, err := r.db.Exec(query, tender.Name, tender.Description, tender.ServiceType, tender.Id,
+		change.Username, change.Kind, change.Comment)
+	return mapError(err)
+}
+
+func (r *tenderRepository) GetVersion(tenderId string, version int) (*repository.TenderVersion, error) {
+	var hist versionHist
+	err := sqlx.Get(r.db, &hist, `SELECT `+versionHistColumns+`
+							FROM tender_version_hist
+							WHERE tender_id = $1 AND version = $2`, tenderId, version)
+	if err != nil {
+		return nil, err
+	}
+	return newTenderVersion(tenderId, &hist)
+}
+
+func (r *tenderRepository) ListVersions(tenderId string) ([]repository.TenderVersion, error) {
+	hists := []versionHist{}
+	err := sqlx.Select(r.db, &hists, `SELECT `+versionHistColumns+`
+							FROM tender_version_hist
+							WHERE tender_id = $1
+							ORDER BY version`, tenderId)
+	if err != nil {
+		return nil, err
+	}
+	tenderVersions := make([]repository.TenderVersion, 0, len(hists))
+	for i := range hists {
+		tenderVersion, err := newTenderVersion(tenderId, &hists[i])
+		if err != nil {
+			return nil, err
+		}
+		tenderVersions = append(tenderVersions, *tenderVersion)
+	}
+	return tenderVersions, nil
+}
+
+func newTenderVersion(tenderId string, hist *versionHist) (*repository.TenderVersion, error) {
+	tenderVersion := repository.TenderVersion{
+		TenderId:   tenderId,
+		Version:    hist.Version,
+		ChangedAt:  hist.ChangedAt,
+		ChangedBy:  hist.ChangedBy,
+		ChangeKind: hist.ChangeKind,
+		Comment:    hist.Comment,
+		Broken:     hist.Broken,
+	}
+	// Из поврежденного снимка берутся поля, которые удалось прочитать
+	err := json.Unmarshal([]byte(hist.Params), &tenderVersion)
+	if err != nil && !hist.Broken {
+		return nil, err
+	}
+	return &tenderVersion, nil
+}
@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"avitoTask/internal/repository"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type webhookRepository struct {
+	db sqlx.Ext
+}
+
+// Строка webhook, массив видов событий читается через pq
+type webhookRow struct {
+	repository.Webhook
+	EventTypes pq.StringArray `db:"event_types"`
+}
+
+func (r *webhookRow) toWebhook() repository.Webhook {
+	webhook := r.Webhook
+	webhook.EventTypes = []string(r.EventTypes)
+	return webhook
+}
+
+const webhookColumns = `id,
+					organization_id,
+					url,
+					secret,
+					event_types,
+					created_by,
+					created_at`
+
+const webhookDeliveryColumns = `id,
+					webhook_id,
+					event_type,
+					payload,
+					status,
+					attempts,
+					COALESCE(response_code, 0) AS response_code,
+					COALESCE(last_error, '') AS last_error,
+					next_attempt_at,
+					created_at,
+					updated_at`
+
+func (r *webhookRepository) Create(webhook *repository.Webhook) error {
+	err := r.db.QueryRowx(`INSERT INTO webhook
+									(organization_id,
+									url,
+									secret,
+									event_types,
+									created_by)
+						VALUES     ($1,
+									$2,
+									$3,
+									$4,
+									$5)
+						RETURNING id, created_at`, webhook.OrganizationId, webhook.Url, webhook.Secret,
+		pq.Array(webhook.EventTypes), webhook.CreatedBy).Scan(&webhook.Id, &webhook.CreatedAt)
+	return mapError(err)
+}
+
+func (r *webhookRepository) Get(webhookId string) (*repository.Webhook, error) {
+	var row webhookRow
+	err := sqlx.Get(r.db, &row, `SELECT `+webhookColumns+`
+								FROM webhook
+								WHERE id = $1`, webhookId)
+	if err != nil {
+		return nil, err
+	}
+	webhook := row.toWebhook()
+	return &webhook, nil
+}
+
+func (r *webhookRepository) ListByOrganization(organizationId string) ([]repository.Webhook, error) {
+	rows := []webhookRow{}
+	err := sqlx.Select(r.db, &rows, `SELECT `+webhookColumns+`
+								FROM webhook
+								WHERE organization_id = $1
+								ORDER BY created_at, id`, organizationId)
+	if err != nil {
+		return nil, err
+	}
+	webhooks := make([]repository.Webhook, 0, len(rows))
+	for i := range rows {
+		webhooks = append(webhooks, rows[i].toWebhook())
+	}
+	return webhooks, nil
+}
+
+func (r *webhookRepository) Delete(webhookId string) error {
+	result, err := r.db.Exec("DELETE FROM webhook WHERE id = $1", webhookId)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *webhookRepository) CreateDelivery(delivery *repository.WebhookDelivery) error {
+	err := r.db.QueryRowx(`INSERT INTO webhook_delivery
+									(webhook_id,
+									event_type,
+									payload,
+									status)
+						VALUES     ($1,
+									$2,
+									$3,
+									$4)
+						RETURNING id, next_attempt_at, created_at, updated_at`, delivery.WebhookId, delivery.EventType,
+		delivery.Payload, delivery.Status).Scan(&delivery.Id, &delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.UpdatedAt)
+	return mapError(err)
+}
+
+func (r *webhookRepository) GetDelivery(deliveryId string) (*repository.WebhookDelivery, error) {
+	var delivery repository.WebhookDelivery
+	err := sqlx.Get(r.db, &delivery, `SELECT `+webhookDeliveryColumns+`
+								FROM webhook_delivery
+								WHERE id = $1`, deliveryId)
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *webhookRepository) UpdateDelivery(delivery *repository.WebhookDelivery, retryIn time.Duration) error {
+	err := r.db.QueryRowx(`UPDATE webhook_delivery
+					SET    status = $1,
+							attempts = $2,
+							response_code = NULLIF($3, 0),
+							last_error = NULLIF($4, ''),
+							next_attempt_at = CURRENT_TIMESTAMP + $5 * INTERVAL '1 millisecond',
+							updated_at = CURRENT_TIMESTAMP
+					WHERE  id = $6
+					RETURNING next_attempt_at, updated_at`, delivery.Status, delivery.Attempts, delivery.ResponseCode,
+		delivery.LastError, retryIn.Milliseconds(), delivery.Id).Scan(&delivery.NextAttemptAt, &delivery.UpdatedAt)
+	return mapError(err)
+}
+
+func (r *webhookRepository) ClaimDue(limit int, lease time.Duration) ([]repository.WebhookDelivery, error) {
+	deliveries := []repository.WebhookDelivery{}
+	err := sqlx.Select(r.db, &deliveries, `UPDATE webhook_delivery
+								SET    next_attempt_at = CURRENT_TIMESTAMP + $2 * INTERVAL '1 millisecond'
+								WHERE  id IN (SELECT id
+											FROM webhook_delivery
+											WHERE status = 'Pending'
+												AND next_attempt_at <= CURRENT_TIMESTAMP
+											ORDER BY next_attempt_at
+											LIMIT $1
+											FOR UPDATE SKIP LOCKED)
+								RETURNING `+webhookDeliveryColumns, limit, lease.Milliseconds())
+	return deliveries, err
+}
+
+func (r *webhookRepository) ListDeliveries(webhookId string, limit, offset int) ([]repository.WebhookDelivery, error) {
+	deliveries := []repository.WebhookDelivery{}
+	err := sqlx.Select(r.db, &deliveries, `SELECT `+webhookDeliveryColumns+`
+								FROM webhook_delivery
+								WHERE webhook_id = $1
+								ORDER BY created_at DESC, id
+								LIMIT $2 OFFSET $3`, webhookId, limit, offset)
+	return deliveries, err
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"errors"
+	"time"
+)
+
+// Нарушение бизнес-правил хранилища: недопустимый переход статуса, изменение закрытого предложения и т.п.
+// Если запись не найдена, методы репозиториев возвращают sql.ErrNoRows.
+type ConflictError struct {
+	Reason string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Reason
+}
+
+func IsConflict(err error) bool {
+	var conflictErr *ConflictError
+	return errors.As(err, &conflictErr)
+}
+
+type TenderRepository interface {
+	Exists(tenderId string) error
+	Get(tenderId string) (*Tender, error)
+	List(filter TenderFilter, page Page) ([]Tender, error)
+	Count(filter TenderFilter) (int, error)
+	// Тендеры организаций, в которых у пользователя есть одна из ролей roles
+	ListByMember(username string, roles []string, page Page) ([]Tender, error)
+	CountByMember(username string, roles []string) (int, error)
+	// Полнотекстовый поиск по названию и описанию, результаты упорядочены по убыванию ранга
+	Search(query string, filter TenderFilter, limit, offset int) ([]FoundTender, error)
+	Create(tender *Tender) error
+	// Изменения статуса, названия, описания и вида услуги сохраняют предыдущую версию в истории вместе с change
+	UpdateStatus(tenderId, status string, change Change) error
+	Update(tender *Tender, change Change) error
+	GetVersion(tenderId string, version int) (*TenderVersion, error)
+	// Сохраненные в истории версии по возрастанию номера, текущая версия в историю не входит
+	ListVersions(tenderId string) ([]TenderVersion, error)
+}
+
+type BidRepository interface {
+	Exists(bidId string) error
+	Get(bidId string) (*Bid, error)
+	ListByTender(tenderId string, filter BidFilter, page Page) ([]Bid, error)
+	CountByTender(tenderId string, filter BidFilter) (int, error)
+	// Количество видимых пользователю предложений тендера по статусам, статусы без предложений не возвращаются
+	CountByStatus(tenderId string, filter BidFilter) (map[string]int, error)
+	// Принятое по тендеру предложение, sql.ErrNoRows - предложение еще не принято
+	GetApproved(tenderId string) (*Bid, error)
+	// Предложения, автором которых является пользователь лично или организация, в которой у него есть одна из ролей roles
+	ListByMember(username string, roles []string, page Page) ([]Bid, error)
+	CountByMember(username string, roles []string) (int, error)
+	// Полнотекстовый поиск по названию и описанию среди предложений всех тендеров,
+	// результаты упорядочены по убыванию ранга
+	Search(query string, filter BidFilter, limit, offset int) ([]FoundBid, error)
+	Create(bid *Bid) error
+	// Изменения статуса, названия и описания сохраняют предыдущую версию в истории вместе с change
+	UpdateStatus(bidId, status string, change Change) error
+	Update(bid *Bid, change Change) error
+	SetDecision(bidId, decision string) error
+	GetVersion(bidId string, version int) (*BidVersion, error)
+	// Сохраненные в истории версии по возрастанию номера, текущая версия в историю не входит
+	ListVersions(bidId string) ([]BidVersion, error)
+	CreateFeedback(feedback *BidFeedback) error
+	// Отзывы на предложения, автором которых является пользователь лично или его организация
+	ListAuthorFeedback(authorUsername string, limit, offset int) ([]BidFeedback, error)
+	// Есть ли у тендера предложение, автором которого является пользователь лично или его организация,
+	// sql.ErrNoRows - предложения нет
+	ExistsByAuthor(tenderId, authorUsername string) error
+}
+
+type DecisionRepository interface {
+	Create(decision *BidDecision) error
+	CountByUser(bidId, username string) (int, error)
+	CountApproved(bidId string) (int, error)
+	// Решения по предложению в порядке принятия
+	ListByBid(bidId string) ([]BidDecision, error)
+}
+
+type EmployeeRepository interface {
+	Exists(username string) error
+	GetId(username string) (string, error)
+}
+
+type OrganizationRepository interface {
+	Exists(organizationId string) error
+	Get(organizationId string) (*Organization, error)
+	// Роли пользователя в организации, ответственные за организацию имеют роль owner
+	GetMemberRoles(organizationId, username string) ([]string, error)
+	CountMembersWithRoles(organizationId string, roles []string) (int, error)
+	GetQuorum(organizationId string) (int, error)
+	SetQuorum(organizationId string, quorum int) error
+	ListRoles(organizationId string) ([]OrganizationRole, error)
+	GrantRole(organizationId, username, role, grantedBy string) (*OrganizationRole, error)
+	RevokeRole(organizationId, username, role string) error
+}
+
+type WebhookRepository interface {
+	Create(webhook *Webhook) error
+	Get(webhookId string) (*Webhook, error)
+	ListByOrganization(organizationId string) ([]Webhook, error)
+	// sql.ErrNoRows - подписка не найдена
+	Delete(webhookId string) error
+	CreateDelivery(delivery *WebhookDelivery) error
+	GetDelivery(deliveryId string) (*WebhookDelivery, error)
+	// Сохраняет статус, количество попыток и итог последней попытки, следующая попытка - через retryIn
+	UpdateDelivery(delivery *WebhookDelivery, retryIn time.Duration) error
+	// Выбирает не больше limit доставок в статусе Pending, время следующей попытки которых наступило,
+	// и откладывает их следующую попытку на lease. Если отправка прервется, доставка будет выбрана снова
+	// по истечении lease. Другие экземпляры сервиса выбранные доставки пропускают
+	ClaimDue(limit int, lease time.Duration) ([]WebhookDelivery, error)
+	// Доставки подписки, новые первыми
+	ListDeliveries(webhookId string, limit, offset int) ([]WebhookDelivery, error)
+}
+
+type OutboxRepository interface {
+	Create(event *OutboxEvent) error
+	// Необработанные получателем sink события по возрастанию id. Выбранные события блокируются
+	// до конца транзакции, другие экземпляры сервиса их пропускают
+	ListPending(sink string, limit int) ([]OutboxEvent, error)
+	MarkProcessed(sink string, eventId int64) error
+	// Id последнего события, 0 - событий нет
+	LastId() (int64, error)
+	// Удаляет события старше olderThan, обработанные всеми получателями sinks, вместе с их отметками.
+	// Возвращает количество удаленных событий
+	DeleteProcessed(sinks []string, olderThan time.Duration) (int64, error)
+}
+
+// Журнал только пополняется, методов изменения и удаления нет
+type AuditRepository interface {
+	Create(entry *AuditEntry) error
+	// Записи по фильтру, новые первыми
+	List(filter AuditFilter, limit, offset int) ([]AuditEntry, error)
+}
+
+type Transactor interface {
+	// Выполняет fn в одной транзакции: изменения фиксируются, только если fn не вернула ошибку
+	InTx(fn func(tx *Store) error) error
+}
+
+type Store struct {
+	Tenders       TenderRepository
+	Bids          BidRepository
+	Decisions     DecisionRepository
+	Employees     EmployeeRepository
+	Organizations OrganizationRepository
+	Webhooks      WebhookRepository
+	Outbox        OutboxRepository
+	Audit         AuditRepository
+	Transactor
+}
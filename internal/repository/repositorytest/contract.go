@@ -0,0 +1,303 @@
+// Общие проверки поведения репозиториев, которые должны одинаково выполняться для памяти и PostgreSQL
+package repositorytest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"avitoTask/internal/repository"
+	"avitoTask/internal/repository/memory"
+
+	"github.com/google/uuid"
+)
+
+// Создает хранилище с сотрудниками и организациями из seed
+type NewStore func(t *testing.T, seed *memory.Seed) *repository.Store
+
+type fixture struct {
+	store          *repository.Store
+	organizationId string
+	authorId       string
+	authorUsername string
+}
+
+// Идентификаторы создаются заново для каждой проверки, поэтому записи разных запусков в одной базе не пересекаются
+func newFixture(t *testing.T, newStore NewStore) *fixture {
+	f := &fixture{
+		organizationId: uuid.NewString(),
+		authorId:       uuid.NewString(),
+		authorUsername: "author-" + uuid.NewString()[:8],
+	}
+	f.store = newStore(t, &memory.Seed{
+		Employees:     []memory.Employee{{Id: f.authorId, Username: f.authorUsername}},
+		Organizations: []memory.Organization{{Id: f.organizationId, Name: "Org", Type: "LLC"}},
+		OrganizationResponsibles: []memory.OrganizationResponsible{
+			{OrganizationId: f.organizationId, UserId: f.authorId},
+		},
+	})
+	return f
+}
+
+// Время создания записей с разной точностью: при сравнении строк 10:00:00Z оказался бы позже 10:00:00.5Z
+var createdAts []string = []string{"2024-05-01T10:00:00.5Z", "2024-05-01T10:00:00Z", "2024-05-01T09:59:59.25Z"}
+
+// Названия записей в порядке возрастания времени создания
+var createdOrder []string = []string{"third", "second", "first"}
+
+func Run(t *testing.T, newStore NewStore) {
+	t.Run("TendersOrderedByCreatedAt", func(t *testing.T) { testTendersOrderedByCreatedAt(t, newFixture(t, newStore)) })
+	t.Run("TendersCursorByCreatedAt", func(t *testing.T) { testTendersCursorByCreatedAt(t, newFixture(t, newStore)) })
+	t.Run("TendersFilteredByCreatedAt", func(t *testing.T) { testTendersFilteredByCreatedAt(t, newFixture(t, newStore)) })
+	t.Run("BidsOrderedByCreatedAt", func(t *testing.T) { testBidsOrderedByCreatedAt(t, newFixture(t, newStore)) })
+	t.Run("SearchHighlightEscapesHtml", func(t *testing.T) { testSearchHighlightEscapesHtml(t, newFixture(t, newStore)) })
+	t.Run("OutboxDeleteProcessed", func(t *testing.T) { testOutboxDeleteProcessed(t, newFixture(t, newStore)) })
+}
+
+func (f *fixture) createTenders(t *testing.T) {
+	t.Helper()
+	names := []string{"first", "second", "third"}
+	for i, createdAt := range createdAts {
+		err := f.store.Tenders.Create(&repository.Tender{
+			Name:           names[i],
+			ServiceType:    "Delivery",
+			Status:         "Published",
+			OrganizationId: f.organizationId,
+			Version:        1,
+			CreatedAt:      createdAt,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func (f *fixture) tenderFilter() repository.TenderFilter {
+	return repository.TenderFilter{OrganizationId: f.organizationId}
+}
+
+func tenderNames(tenders []repository.Tender) []string {
+	names := make([]string, 0, len(tenders))
+	for _, tender := range tenders {
+		names = append(names, tender.Name)
+	}
+	return names
+}
+
+func checkNames(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("получены %v, ожидались %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("получены %v, ожидались %v", got, want)
+		}
+	}
+}
+
+func reversed(names []string) []string {
+	result := make([]string, 0, len(names))
+	for i := len(names) - 1; i >= 0; i-- {
+		result = append(result, names[i])
+	}
+	return result
+}
+
+func testTendersOrderedByCreatedAt(t *testing.T, f *fixture) {
+	f.createTenders(t)
+	tenders, err := f.store.Tenders.List(f.tenderFilter(), repository.Page{Limit: 10, Sort: repository.SortByCreatedAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNames(t, tenderNames(tenders), createdOrder)
+
+	tenders, err = f.store.Tenders.List(f.tenderFilter(), repository.Page{Limit: 10, Sort: repository.SortByCreatedAt, Desc: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNames(t, tenderNames(tenders), reversed(createdOrder))
+}
+
+func testTendersCursorByCreatedAt(t *testing.T, f *fixture) {
+	f.createTenders(t)
+	page := repository.Page{Limit: 1, Sort: repository.SortByCreatedAt}
+	var names []string
+	for range createdOrder {
+		tenders, err := f.store.Tenders.List(f.tenderFilter(), page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tenders) != 1 {
+			t.Fatalf("страница после %v содержит %d тендеров", names, len(tenders))
+		}
+		names = append(names, tenders[0].Name)
+		key := tenders[0].PageKey(page.Sort)
+		page.After = &key
+	}
+	checkNames(t, names, createdOrder)
+
+	tenders, err := f.store.Tenders.List(f.tenderFilter(), page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNames(t, tenderNames(tenders), nil)
+}
+
+func testTendersFilteredByCreatedAt(t *testing.T, f *fixture) {
+	f.createTenders(t)
+	from := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	filter := f.tenderFilter()
+	filter.CreatedFrom = &from
+	tenders, err := f.store.Tenders.List(filter, repository.Page{Limit: 10, Sort: repository.SortByCreatedAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNames(t, tenderNames(tenders), []string{"second", "first"})
+
+	filter = f.tenderFilter()
+	filter.CreatedTo = &from
+	count, err := f.store.Tenders.Count(filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("до %s создано %d тендеров, ожидалось 2", from, count)
+	}
+}
+
+func testBidsOrderedByCreatedAt(t *testing.T, f *fixture) {
+	tender := &repository.Tender{
+		Name:           "tender",
+		ServiceType:    "Delivery",
+		Status:         "Published",
+		OrganizationId: f.organizationId,
+		Version:        1,
+		CreatedAt:      createdAts[0],
+	}
+	err := f.store.Tenders.Create(tender)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{"first", "second", "third"}
+	for i, createdAt := range createdAts {
+		err := f.store.Bids.Create(&repository.Bid{
+			Name:       names[i],
+			Status:     "Created",
+			TenderId:   tender.Id,
+			AuthorType: "User",
+			AuthorId:   f.authorId,
+			Version:    1,
+			CreatedAt:  createdAt,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bids, err := f.store.Bids.ListByTender(tender.Id, repository.BidFilter{ViewerUsername: f.authorUsername},
+		repository.Page{Limit: 10, Sort: repository.SortByCreatedAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bidNames := make([]string, 0, len(bids))
+	for _, bid := range bids {
+		bidNames = append(bidNames, bid.Name)
+	}
+	checkNames(t, bidNames, createdOrder)
+}
+
+// В выделенных совпадениях тегами остаются только <b></b>, пользовательский текст экранируется
+func testSearchHighlightEscapesHtml(t *testing.T, f *fixture) {
+	err := f.store.Tenders.Create(&repository.Tender{
+		Name:           `Доставка <script>alert("x")</script>`,
+		Description:    `Доставка & <img src=x onerror=alert(1)>`,
+		ServiceType:    "Delivery",
+		Status:         "Published",
+		OrganizationId: f.organizationId,
+		Version:        1,
+		CreatedAt:      createdAts[0],
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := f.store.Tenders.Search("доставка", f.tenderFilter(), 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 {
+		t.Fatalf("найдено тендеров %d, ожидался 1", len(found))
+	}
+	for _, highlight := range []string{found[0].NameHighlight, found[0].DescriptionHighlight} {
+		if !strings.Contains(highlight, "<b>Доставка</b>") {
+			t.Errorf("в %s не выделено совпадение", highlight)
+		}
+		unescaped := strings.NewReplacer("<b>", "", "</b>", "").Replace(highlight)
+		if strings.ContainsAny(unescaped, `<>"`) {
+			t.Errorf("в %s остался неэкранированный HTML", highlight)
+		}
+	}
+}
+
+// Удаляются события, обработанные всеми переданными получателями. Имена получателей уникальны,
+// поэтому события других проверок в той же базе не затрагиваются
+func testOutboxDeleteProcessed(t *testing.T, f *fixture) {
+	f.createTenders(t)
+	tenders, err := f.store.Tenders.List(f.tenderFilter(), repository.Page{Limit: 1, Sort: repository.SortByCreatedAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sinks := []string{"a-" + uuid.NewString()[:8], "b-" + uuid.NewString()[:8]}
+	var ids []int64
+	for range 2 {
+		event := repository.OutboxEvent{EventType: "tender_closed", TenderId: tenders[0].Id, Payload: `{}`}
+		err := f.store.Outbox.Create(&event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, event.Id)
+	}
+	for _, mark := range []struct {
+		sink    string
+		eventId int64
+	}{{sinks[0], ids[0]}, {sinks[1], ids[0]}, {sinks[0], ids[1]}} {
+		err := f.store.Outbox.MarkProcessed(mark.sink, mark.eventId)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	lastId, err := f.store.Outbox.LastId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastId < ids[1] {
+		t.Fatalf("последний id %d, ожидалось не меньше %d", lastId, ids[1])
+	}
+
+	deleted, err := f.store.Outbox.DeleteProcessed(sinks, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 0 {
+		t.Fatalf("удалено %d новых событий", deleted)
+	}
+	time.Sleep(10 * time.Millisecond)
+	_, err = f.store.Outbox.DeleteProcessed(sinks, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outboxEvents, err := f.store.Outbox.ListPending("c-"+uuid.NewString()[:8], 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kept []int64
+	for _, event := range outboxEvents {
+		if event.Id == ids[0] || event.Id == ids[1] {
+			kept = append(kept, event.Id)
+		}
+	}
+	if len(kept) != 1 || kept[0] != ids[1] {
+		t.Fatalf("остались события %v, ожидалось только %d", kept, ids[1])
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"database/sql"
+
+	validator "avitoTask/internal"
+	"avitoTask/internal/audit"
+	"avitoTask/internal/auth"
+	"avitoTask/internal/repository"
+)
+
+type AuditService struct {
+	store     *repository.Store
+	validator *validator.Validator
+	auth      *auth.Auth
+}
+
+func NewAuditService(store *repository.Store, validator *validator.Validator, auth *auth.Auth) *AuditService {
+	return &AuditService{store: store, validator: validator, auth: auth}
+}
+
+// Записи журнала организации filter.OrganizationId, новые первыми
+func (s *AuditService) List(username string, filter repository.AuditFilter, limit, offset int) ([]repository.AuditEntry, error) {
+	err := s.validator.CheckOrganizationExists(filter.OrganizationId)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrOrganizationNotFound)
+	}
+	err = s.auth.Authorize(username, filter.OrganizationId, auth.ActionViewAudit)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrUserCannotViewAudit)
+	}
+
+	entries, err := s.store.Audit.List(filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	err = s.hideInvisibleBids(username, entries)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Убирает снимки предложений, которые пользователь не может просматривать: записи о чужих
+// неопубликованных и отмененных предложениях видны без их содержимого
+func (s *AuditService) hideInvisibleBids(username string, entries []repository.AuditEntry) error {
+	visibleBids := map[string]bool{}
+	for i := range entries {
+		if entries[i].EntityType != audit.EntityBid {
+			continue
+		}
+		bidId := entries[i].EntityId
+		visible, ok := visibleBids[bidId]
+		if !ok {
+			err := s.auth.CheckUserViewBid(username, bidId)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+			visible = err == nil
+			visibleBids[bidId] = visible
+		}
+		if !visible {
+			entries[i].Before, entries[i].After = "", ""
+		}
+	}
+	return nil
+}
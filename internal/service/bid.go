@@ -0,0 +1,620 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	validator "avitoTask/internal"
+	"avitoTask/internal/audit"
+	"avitoTask/internal/auth"
+	"avitoTask/internal/events"
+	"avitoTask/internal/metrics"
+	"avitoTask/internal/outbox"
+	"avitoTask/internal/repository"
+)
+
+// Допустимые переходы статусов предложения, дублируются в таблице bid_status_transition
+var BidStatusTransitions map[string][]string = map[string][]string{
+	"Created":   {"Published", "Canceled"},
+	"Published": {"Canceled"},
+	"Canceled":  {},
+}
+
+func CheckBidStatusTransition(from, to string) bool {
+	return from == to || slices.Contains(BidStatusTransitions[from], to)
+}
+
+// Кворум по умолчанию, если для организации не задан свой (PUT /api/organizations/:organizationId/quorum).
+// Итоговый кворум не больше числа сотрудников организации, которые могут согласовывать предложения
+const Quorum int = 3
+
+// Изменяемые поля предложения, nil - поле не меняется
+type BidEdit struct {
+	Name        *string
+	Description *string
+}
+
+// Предложение с решениями согласующих и количеством согласований относительно кворума.
+// Предложение видят только организация тендера и сторона автора, поэтому имена согласующих
+// не раскрываются посторонним
+type BidDetail struct {
+	Bid           *repository.Bid
+	Decisions     []repository.BidDecision
+	ApprovedCount int
+	RejectedCount int
+	Quorum        int
+}
+
+type BidService struct {
+	store     *repository.Store
+	validator *validator.Validator
+	auth      *auth.Auth
+	relay     outbox.Notifier
+}
+
+func NewBidService(store *repository.Store, validator *validator.Validator, auth *auth.Auth,
+	relay outbox.Notifier) *BidService {
+	return &BidService{store: store, validator: validator, auth: auth, relay: relay}
+}
+
+// Предложения тендера, которые видит пользователь: свои в любом статусе, а опубликованные -
+// если он может просматривать предложения организации тендера. Возвращает также общее количество
+func (s *BidService) ListByTender(username, tenderId string, statuses []string, page repository.Page) ([]repository.Bid, int, error) {
+	err := s.validator.CheckTenderExists(tenderId)
+	if err != nil {
+		return nil, 0, replaceNoRows(err, ErrTenderNotFound)
+	}
+
+	filter := repository.BidFilter{
+		Statuses:       statuses,
+		ViewerUsername: username,
+		ViewerRoles:    auth.RolesWithPermission(auth.ActionViewBid),
+	}
+	bids, err := s.store.Bids.ListByTender(tenderId, filter, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.store.Bids.CountByTender(tenderId, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bids, total, nil
+}
+
+// Собственные предложения пользователя и предложения организаций, в которых он может управлять предложениями
+func (s *BidService) ListByMember(username string, page repository.Page) ([]repository.Bid, int, error) {
+	roles := auth.RolesWithPermission(auth.ActionManageBid)
+	bids, err := s.store.Bids.ListByMember(username, roles, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.store.Bids.CountByMember(username, roles)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bids, total, nil
+}
+
+func (s *BidService) GetDetail(username, bidId string) (*BidDetail, error) {
+	bid, err := s.getVisible(username, bidId)
+	if err != nil {
+		return nil, err
+	}
+
+	decisions, err := s.store.Decisions.ListByBid(bid.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	approvedCnt, err := s.store.Decisions.CountApproved(bid.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	quorum, err := getQuorum(s.store, bid.TenderId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BidDetail{
+		Bid:           bid,
+		Decisions:     decisions,
+		ApprovedCount: approvedCnt,
+		RejectedCount: len(decisions) - approvedCnt,
+		Quorum:        quorum,
+	}, nil
+}
+
+func (s *BidService) GetStatus(username, bidId string) (string, error) {
+	bid, err := s.getVisible(username, bidId)
+	if err != nil {
+		return "", err
+	}
+	return bid.Status, nil
+}
+
+// Версии предложения из истории и последней идет текущая версия
+func (s *BidService) ListVersions(username, bidId string) ([]repository.BidVersion, error) {
+	bid, err := s.getVisible(username, bidId)
+	if err != nil {
+		return nil, err
+	}
+
+	bidVersions, err := s.store.Bids.ListVersions(bid.Id)
+	if err != nil {
+		return nil, err
+	}
+	return append(bidVersions, *currentBidVersion(bid)), nil
+}
+
+func (s *BidService) GetVersion(username, bidId string, version int) (*repository.BidVersion, error) {
+	bid, err := s.getVisible(username, bidId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getVersion(bid, version)
+}
+
+// Сравнение версии from с версией to, при to = 0 сравнивается с текущей версией
+func (s *BidService) Diff(username, bidId string, from, to int) (*VersionDiff, error) {
+	bid, err := s.getVisible(username, bidId)
+	if err != nil {
+		return nil, err
+	}
+
+	if to == 0 {
+		to = bid.Version
+	}
+	fromVersion, err := s.getVersion(bid, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := s.getVersion(bid, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VersionDiff{
+		From: from,
+		To:   to,
+		Changes: diffFields(
+			diffField{"name", fromVersion.Name, toVersion.Name},
+			diffField{"description", fromVersion.Description, toVersion.Description},
+		),
+		DescriptionDiff: unifiedDiff(fmt.Sprintf("version %d", from), fmt.Sprintf("version %d", to),
+			fromVersion.Description, toVersion.Description),
+	}, nil
+}
+
+// Создает предложение в статусе Created с первой версией, текущим временем создания и без решения,
+// переданные значения не учитываются: публикация идет через ChangeStatus, который пишет событие
+// bid_published и учитывает предложение в метриках
+func (s *BidService) Create(actor audit.Actor, bid *repository.Bid) error {
+	bid.Status = "Created"
+	bid.Version = 1
+	bid.CreatedAt = time.Now().UTC().Format(time.RFC3339Nano)
+	bid.Decision = nil
+
+	err := s.validator.CheckTenderExists(bid.TenderId)
+	if err != nil {
+		return replaceNoRows(err, ErrTenderNotFound)
+	}
+
+	err = s.auth.CheckUserCanManageBid(actor.Username, bid.AuthorType, bid.AuthorId)
+	if err != nil {
+		return replaceNoRows(err, ErrUserNotAuthor)
+	}
+
+	err = s.validator.CheckTenderPublished(bid.TenderId)
+	if err != nil {
+		return replaceNoRows(err, ErrTenderNotPublished)
+	}
+
+	err = s.store.InTx(func(tx *repository.Store) error {
+		err := tx.Bids.Create(bid)
+		if err != nil {
+			return err
+		}
+		return recordBid(tx, actor, audit.ActionCreate, bid.Id, bid.TenderId, nil, bid)
+	})
+	if err != nil {
+		return replaceConflict(err)
+	}
+	return nil
+}
+
+func (s *BidService) ChangeStatus(actor audit.Actor, bidId, status, comment string) (*repository.Bid, error) {
+	bid, err := s.getEditable(actor.Username, bidId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !CheckBidStatusTransition(bid.Status, status) {
+		return nil, ErrInvalidBidStatusTransition
+	}
+	if status == "Published" && bid.Status != status {
+		err = s.validator.CheckTenderPublished(bid.TenderId)
+		if err != nil {
+			return nil, replaceNoRows(err, ErrTenderNotPublished)
+		}
+	}
+
+	updated, err := s.update(actor, audit.ActionStatusChange, bid, func(tx *repository.Store) error {
+		err := tx.Bids.UpdateStatus(bid.Id, status, repository.Change{
+			Username: actor.Username,
+			Kind:     repository.ChangeKindStatusChange,
+			Comment:  comment,
+		})
+		if err != nil || status != "Published" || bid.Status == status {
+			return err
+		}
+		return outbox.Add(tx, newBidEvent(events.BidPublished, bid, status))
+	})
+	if err != nil {
+		return nil, replaceConflict(err)
+	}
+	s.relay.Notify()
+	if status == "Published" && bid.Status != status {
+		metrics.BidsSubmitted.Inc()
+	}
+
+	return updated, nil
+}
+
+func (s *BidService) Edit(actor audit.Actor, bidId string, edit BidEdit, comment string) (*repository.Bid, error) {
+	bid, err := s.getEditable(actor.Username, bidId)
+	if err != nil {
+		return nil, err
+	}
+
+	edited := *bid
+	if edit.Name != nil {
+		edited.Name = *edit.Name
+	}
+	if edit.Description != nil {
+		edited.Description = *edit.Description
+	}
+	updated, err := s.update(actor, audit.ActionEdit, bid, func(tx *repository.Store) error {
+		return tx.Bids.Update(&edited, repository.Change{
+			Username: actor.Username,
+			Kind:     repository.ChangeKindEdit,
+			Comment:  comment,
+		})
+	})
+	if err != nil {
+		return nil, replaceConflict(err)
+	}
+
+	return updated, nil
+}
+
+// Откат создает новую версию с названием и описанием из указанной версии
+func (s *BidService) Rollback(actor audit.Actor, bidId string, version int, comment string) (*repository.Bid, error) {
+	bid, err := s.getEditable(actor.Username, bidId)
+	if err != nil {
+		return nil, err
+	}
+
+	if version >= bid.Version {
+		return nil, ErrInvalidVersion
+	}
+
+	bidVersion, err := s.store.Bids.GetVersion(bid.Id, version)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrVersionNotFound)
+	}
+	if bidVersion.Broken {
+		return nil, ErrVersionBroken
+	}
+
+	rolledBack := *bid
+	rolledBack.Name = bidVersion.Name
+	rolledBack.Description = bidVersion.Description
+	updated, err := s.update(actor, audit.ActionRollback, bid, func(tx *repository.Store) error {
+		return tx.Bids.Update(&rolledBack, repository.Change{
+			Username: actor.Username,
+			Kind:     repository.ChangeKindRollback,
+			Comment:  comment,
+		})
+	})
+	if err != nil {
+		return nil, replaceConflict(err)
+	}
+
+	return updated, nil
+}
+
+// Выполняет изменение предложения в транзакции вместе с записью журнала аудита, возвращает предложение после изменения
+func (s *BidService) update(actor audit.Actor, action string, before *repository.Bid,
+	change func(tx *repository.Store) error) (*repository.Bid, error) {
+	var after *repository.Bid
+	err := s.store.InTx(func(tx *repository.Store) error {
+		err := change(tx)
+		if err != nil {
+			return err
+		}
+		after, err = tx.Bids.Get(before.Id)
+		if err != nil {
+			return err
+		}
+		return recordBid(tx, actor, action, before.Id, before.TenderId, before, after)
+	})
+	return after, err
+}
+
+// Записывает в журнал аудита изменение предложения, организация берется из тендера
+func recordBid(tx *repository.Store, actor audit.Actor, action, bidId, tenderId string, before, after any) error {
+	tender, err := tx.Tenders.Get(tenderId)
+	if err != nil {
+		return err
+	}
+	return audit.Record(tx, actor, audit.Change{
+		Action:         action,
+		EntityType:     audit.EntityBid,
+		EntityId:       bidId,
+		OrganizationId: tender.OrganizationId,
+		Before:         before,
+		After:          after,
+	})
+}
+
+// Расширенный процесс согласования. Отклонение сразу становится решением по предложению,
+// при наборе кворума согласований предложение принимается, а тендер закрывается
+func (s *BidService) SubmitDecision(actor audit.Actor, bidId, decision string) (*repository.Bid, error) {
+	err := s.validator.CheckBidExists(bidId)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrBidNotFound)
+	}
+
+	bid, err := s.store.Bids.Get(bidId)
+	if err != nil {
+		return nil, err
+	}
+
+	if bid.Decision != nil {
+		return nil, ErrBidHasDecision
+	}
+
+	err = s.validator.CheckBidEditable(bid.Id)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrBidReadOnly)
+	}
+
+	decisionCnt, err := s.store.Decisions.CountByUser(bid.Id, actor.Username)
+	if err != nil {
+		return nil, err
+	}
+	if decisionCnt >= 1 {
+		return nil, ErrUserHasDecision
+	}
+
+	err = s.auth.AuthorizeTender(actor.Username, bid.TenderId, auth.ActionApproveBid)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrUserNotResponsible)
+	}
+
+	var closedByQuorum bool
+	err = s.store.InTx(func(tx *repository.Store) error {
+		bidDecision := repository.BidDecision{BidId: bid.Id, Username: actor.Username, Decision: decision}
+		err := tx.Decisions.Create(&bidDecision)
+		if err != nil {
+			return err
+		}
+		err = recordBid(tx, actor, audit.ActionDecision, bid.Id, bid.TenderId, nil, &bidDecision)
+		if err != nil {
+			return err
+		}
+
+		if decision == "Rejected" {
+			return setBidDecision(tx, bid, decision)
+		}
+
+		decisionCnt, err := tx.Decisions.CountApproved(bid.Id)
+		if err != nil {
+			return err
+		}
+
+		quorum, err := getQuorum(tx, bid.TenderId)
+		if err != nil {
+			return err
+		}
+		if decisionCnt < quorum {
+			return nil
+		}
+		err = setBidDecision(tx, bid, decision)
+		if err != nil {
+			return err
+		}
+		tender, err := tx.Tenders.Get(bid.TenderId)
+		if err != nil {
+			return err
+		}
+		err = tx.Tenders.UpdateStatus(bid.TenderId, "Closed", repository.Change{
+			Username: actor.Username,
+			Kind:     repository.ChangeKindStatusChange,
+			Comment:  "Тендер закрыт по кворуму согласований",
+		})
+		if err != nil {
+			return err
+		}
+		closed, err := tx.Tenders.Get(bid.TenderId)
+		if err != nil {
+			return err
+		}
+		err = audit.Record(tx, actor, audit.Change{
+			Action:         audit.ActionStatusChange,
+			EntityType:     audit.EntityTender,
+			EntityId:       tender.Id,
+			OrganizationId: tender.OrganizationId,
+			Before:         tender,
+			After:          closed,
+		})
+		if err != nil {
+			return err
+		}
+		closedByQuorum = true
+		return outbox.Add(tx, newTenderEvent(events.TenderClosed, tender, "Closed"))
+	})
+	if err != nil {
+		return nil, replaceConflict(err)
+	}
+	s.relay.Notify()
+	metrics.BidDecisions.WithLabelValues(decision).Inc()
+	if closedByQuorum {
+		metrics.TendersClosedByQuorum.Inc()
+	}
+
+	return bid, nil
+}
+
+// Сохраняет итоговое решение по предложению вместе с событием о нем
+func setBidDecision(tx *repository.Store, bid *repository.Bid, decision string) error {
+	err := tx.Bids.SetDecision(bid.Id, decision)
+	if err != nil {
+		return err
+	}
+	event := newBidEvent(events.BidDecision, bid, bid.Status)
+	event.Decision = decision
+	return outbox.Add(tx, event)
+}
+
+// Событие о предложении bid в статусе status
+func newBidEvent(eventType string, bid *repository.Bid, status string) events.Event {
+	event := events.NewEvent(eventType, bid.TenderId)
+	event.BidId = bid.Id
+	event.AuthorType = bid.AuthorType
+	event.AuthorId = bid.AuthorId
+	event.Status = status
+	return event
+}
+
+func (s *BidService) Feedback(actor audit.Actor, bidId, description string) (*repository.Bid, error) {
+	err := s.validator.CheckBidExists(bidId)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrBidNotFound)
+	}
+
+	bid, err := s.store.Bids.Get(bidId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.auth.AuthorizeTender(actor.Username, bid.TenderId, auth.ActionApproveBid)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrUserNotResponsible)
+	}
+
+	err = s.store.InTx(func(tx *repository.Store) error {
+		feedback := repository.BidFeedback{BidId: bid.Id, Username: actor.Username, Description: description}
+		err := tx.Bids.CreateFeedback(&feedback)
+		if err != nil {
+			return err
+		}
+		return recordBid(tx, actor, audit.ActionFeedback, bid.Id, bid.TenderId, nil, &feedback)
+	})
+	if err != nil {
+		return nil, replaceConflict(err)
+	}
+	return bid, nil
+}
+
+// Отзывы на предложения автора для сотрудников организации тендера, которые согласовывают предложения.
+// Отзывы доступны, только если автор подал предложение на этот тендер
+func (s *BidService) ListAuthorFeedback(username, tenderId, authorUsername string, limit, offset int) ([]repository.BidFeedback, error) {
+	err := s.validator.CheckTenderExists(tenderId)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrTenderNotFound)
+	}
+
+	err = s.validator.CheckUserExists(authorUsername)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrAuthorNotFound)
+	}
+
+	err = s.auth.AuthorizeTender(username, tenderId, auth.ActionApproveBid)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrUserNotResponsible)
+	}
+
+	err = s.store.Bids.ExistsByAuthor(tenderId, authorUsername)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrAuthorBidNotFound)
+	}
+
+	return s.store.Bids.ListAuthorFeedback(authorUsername, limit, offset)
+}
+
+func currentBidVersion(bid *repository.Bid) *repository.BidVersion {
+	return &repository.BidVersion{
+		BidId:       bid.Id,
+		Version:     bid.Version,
+		Name:        bid.Name,
+		Description: bid.Description,
+		Status:      bid.Status,
+	}
+}
+
+func (s *BidService) getVersion(bid *repository.Bid, version int) (*repository.BidVersion, error) {
+	if version == bid.Version {
+		return currentBidVersion(bid), nil
+	}
+	bidVersion, err := s.store.Bids.GetVersion(bid.Id, version)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrVersionNotFound)
+	}
+	return bidVersion, nil
+}
+
+// Предложение, которое пользователь может просматривать
+func (s *BidService) getVisible(username, bidId string) (*repository.Bid, error) {
+	err := s.validator.CheckBidExists(bidId)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrBidNotFound)
+	}
+
+	err = s.auth.CheckUserViewBid(username, bidId)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrUserCannotViewBid)
+	}
+
+	return s.store.Bids.Get(bidId)
+}
+
+// Предложение, которое пользователь может изменять
+func (s *BidService) getEditable(username, bidId string) (*repository.Bid, error) {
+	err := s.validator.CheckBidExists(bidId)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrBidNotFound)
+	}
+
+	bid, err := s.store.Bids.Get(bidId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.auth.CheckUserCanManageBid(username, bid.AuthorType, bid.AuthorId)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrUserNotAuthor)
+	}
+
+	err = s.validator.CheckBidEditable(bid.Id)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrBidReadOnly)
+	}
+	return bid, nil
+}
+
+// Кворум организации тендера: не больше числа сотрудников, которые могут согласовывать предложения
+func getQuorum(store *repository.Store, tenderId string) (int, error) {
+	tender, err := store.Tenders.Get(tenderId)
+	if err != nil {
+		return 0, err
+	}
+	quorum, err := getOrganizationQuorum(store, tender.OrganizationId)
+	if err != nil {
+		return 0, err
+	}
+	return quorum.Effective, nil
+}
@@ -0,0 +1,367 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"avitoTask/internal/events"
+	"avitoTask/internal/metrics"
+	"avitoTask/internal/repository"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestBidStatusLifecycle(t *testing.T) {
+	s := newTestServices(t)
+	tender := s.publishedTender()
+	bid := s.createBid(tender.Id)
+
+	published, err := s.bids.ChangeStatus(actor("bob"), bid.Id, "Published", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published.Status != "Published" || published.Version != 2 {
+		t.Fatalf("предложение %+v, ожидалась опубликованная версия 2", published)
+	}
+
+	canceled, err := s.bids.ChangeStatus(actor("bob"), bid.Id, "Canceled", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if canceled.Status != "Canceled" {
+		t.Fatalf("статус %s, ожидался Canceled", canceled.Status)
+	}
+
+	_, err = s.bids.ChangeStatus(actor("bob"), bid.Id, "Published", "")
+	if !errors.Is(err, ErrInvalidBidStatusTransition) {
+		t.Fatalf("публикация отмененного предложения: %v", err)
+	}
+}
+
+// Поданным считается только опубликованное предложение, черновики в метрику не попадают
+func TestBidsSubmittedCountedOnPublication(t *testing.T) {
+	s := newTestServices(t)
+	tender := s.publishedTender()
+	before := testutil.ToFloat64(metrics.BidsSubmitted)
+
+	bid := s.createBid(tender.Id)
+	if submitted := testutil.ToFloat64(metrics.BidsSubmitted) - before; submitted != 0 {
+		t.Fatalf("после создания черновика подано %v предложений, ожидалось 0", submitted)
+	}
+
+	_, err := s.bids.ChangeStatus(actor("bob"), bid.Id, "Published", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.bids.ChangeStatus(actor("bob"), bid.Id, "Published", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if submitted := testutil.ToFloat64(metrics.BidsSubmitted) - before; submitted != 1 {
+		t.Fatalf("после публикации подано %v предложений, ожидалось 1", submitted)
+	}
+}
+
+// Предложение, переданное со статусом Published, создается черновиком: иначе оно миновало бы
+// событие bid_published и метрику поданных предложений
+func TestBidCreatedAsDraft(t *testing.T) {
+	s := newTestServices(t)
+	tender := s.publishedTender()
+	before := testutil.ToFloat64(metrics.BidsSubmitted)
+	decision := "Approved"
+	bid := &repository.Bid{
+		Name:       "Предложение",
+		Status:     "Published",
+		TenderId:   tender.Id,
+		AuthorType: "User",
+		AuthorId:   testBobId,
+		Version:    5,
+		Decision:   &decision,
+		CreatedAt:  time.Now().Format(time.RFC3339),
+	}
+	err := s.bids.Create(actor("bob"), bid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := s.store.Bids.Get(bid.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != "Created" || stored.Version != 1 || stored.Decision != nil {
+		t.Fatalf("предложение %+v, ожидался черновик версии 1 без решения", stored)
+	}
+	if submitted := testutil.ToFloat64(metrics.BidsSubmitted) - before; submitted != 0 {
+		t.Fatalf("после создания подано %v предложений, ожидалось 0", submitted)
+	}
+
+	_, err = s.bids.ChangeStatus(actor("bob"), bid.Id, "Published", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err := s.store.Outbox.ListPending("test", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.ContainsFunc(pending, func(event repository.OutboxEvent) bool {
+		return event.EventType == events.BidPublished && strings.Contains(event.Payload, bid.Id)
+	}) {
+		t.Fatalf("события %+v, ожидалось bid_published", pending)
+	}
+}
+
+func TestBidCannotBeCreatedForUnpublishedTender(t *testing.T) {
+	s := newTestServices(t)
+	tender := s.createTender()
+	err := s.bids.Create(actor("bob"), &repository.Bid{
+		Name: "Предложение", Status: "Created", TenderId: tender.Id, AuthorType: "User", AuthorId: testBobId, Version: 1,
+	})
+	if !errors.Is(err, ErrTenderNotPublished) {
+		t.Fatalf("предложение к неопубликованному тендеру: %v", err)
+	}
+}
+
+// Кворум равен min(3, количество согласующих): alice и два approver
+func TestBidApprovedByQuorum(t *testing.T) {
+	s := newTestServices(t)
+	s.grantRole("carol", "approver")
+	s.grantRole("erin", "approver")
+	tender := s.publishedTender()
+	bid := s.publishedBid(tender.Id)
+
+	for _, username := range []string{"alice", "carol"} {
+		_, err := s.bids.SubmitDecision(actor(username), bid.Id, "Approved")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := s.bids.SubmitDecision(actor("carol"), bid.Id, "Approved")
+	if !errors.Is(err, ErrUserHasDecision) {
+		t.Fatalf("повторное решение: %v", err)
+	}
+	current, err := s.store.Bids.Get(bid.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Decision != nil {
+		t.Fatalf("решение %s принято до набора кворума", *current.Decision)
+	}
+
+	_, err = s.bids.SubmitDecision(actor("erin"), bid.Id, "Approved")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err = s.store.Bids.Get(bid.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Decision == nil || *current.Decision != "Approved" {
+		t.Fatal("предложение не принято после набора кворума")
+	}
+	closed, err := s.store.Tenders.Get(tender.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closed.Status != "Closed" {
+		t.Fatalf("статус тендера %s, ожидался Closed", closed.Status)
+	}
+}
+
+func TestBidApprovedByOrganizationQuorum(t *testing.T) {
+	s := newTestServices(t)
+	s.grantRole("carol", "approver")
+	err := s.store.Organizations.SetQuorum(testOrganizationId, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tender := s.publishedTender()
+	bid := s.publishedBid(tender.Id)
+
+	_, err = s.bids.SubmitDecision(actor("carol"), bid.Id, "Approved")
+	if err != nil {
+		t.Fatal(err)
+	}
+	detail, err := s.bids.GetDetail("alice", bid.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.Quorum != 1 || detail.Bid.Decision == nil || *detail.Bid.Decision != "Approved" {
+		t.Fatalf("кворум %d, решение %v, ожидалось согласование одним голосом", detail.Quorum, detail.Bid.Decision)
+	}
+}
+
+// Имена согласующих видят организация тендера и автор предложения, посторонним предложение недоступно
+func TestBidDetailHidesApproversFromOutsiders(t *testing.T) {
+	s := newTestServices(t)
+	s.grantRole("carol", "approver")
+	tender := s.publishedTender()
+	bid := s.publishedBid(tender.Id)
+	_, err := s.bids.SubmitDecision(actor("alice"), bid.Id, "Approved")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, username := range []string{"alice", "carol", "bob"} {
+		detail, err := s.bids.GetDetail(username, bid.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(detail.Decisions) != 1 || detail.Decisions[0].Username != "alice" {
+			t.Errorf("%s: решения %+v, ожидалось решение alice", username, detail.Decisions)
+		}
+	}
+
+	_, err = s.bids.GetDetail("erin", bid.Id)
+	if !errors.Is(err, ErrUserCannotViewBid) {
+		t.Errorf("предложение с решениями доступно постороннему пользователю: %v", err)
+	}
+}
+
+// Отзывы на автора доступны только по тендеру, на который он подал предложение
+func TestAuthorFeedbackRequiresBidOnTender(t *testing.T) {
+	s := newTestServices(t)
+	tender := s.publishedTender()
+	other := s.publishedTender()
+	bid := s.publishedBid(tender.Id)
+	_, err := s.bids.Feedback(actor("alice"), bid.Id, "Хороший исполнитель")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feedback, err := s.bids.ListAuthorFeedback("alice", tender.Id, "bob", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feedback) != 1 {
+		t.Fatalf("отзывов %d, ожидался 1", len(feedback))
+	}
+
+	_, err = s.bids.ListAuthorFeedback("alice", other.Id, "bob", 10, 0)
+	if !errors.Is(err, ErrAuthorBidNotFound) {
+		t.Fatalf("отзывы по тендеру без предложений автора: %v", err)
+	}
+	_, err = s.bids.ListAuthorFeedback("alice", tender.Id, "carol", 10, 0)
+	if !errors.Is(err, ErrAuthorBidNotFound) {
+		t.Fatalf("отзывы на сотрудника без предложений: %v", err)
+	}
+}
+
+// В списке своих предложений нет предложений организаций, где у пользователя только роль viewer
+func TestBidListByMemberRequiresManageRole(t *testing.T) {
+	s := newTestServices(t)
+	tender := s.publishedTender()
+	s.createBid(tender.Id)
+	err := s.bids.Create(actor("alice"), &repository.Bid{
+		Name:       "Предложение организации",
+		Status:     "Created",
+		TenderId:   tender.Id,
+		AuthorType: "Organization",
+		AuthorId:   testOrganizationId,
+		Version:    1,
+		CreatedAt:  time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.grantRole("carol", "viewer")
+
+	_, total, err := s.bids.ListByMember("carol", repository.Page{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 {
+		t.Fatalf("наблюдателю доступно предложений %d, ожидалось 0", total)
+	}
+	bids, total, err := s.bids.ListByMember("bob", repository.Page{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(bids) != 1 || bids[0].AuthorType != "User" {
+		t.Fatalf("автору доступны предложения %+v, ожидалось собственное", bids)
+	}
+
+	s.grantRole("carol", "tender_manager")
+	_, total, err = s.bids.ListByMember("carol", repository.Page{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 {
+		t.Fatalf("менеджеру доступно предложений %d, ожидался 1", total)
+	}
+}
+
+// Отдельное предложение видят те же пользователи, что и в списке предложений тендера: сторона автора
+// в любом статусе, а опубликованное - еще и сотрудники организации тендера с ролью
+func TestBidVisibilityMatchesTenderList(t *testing.T) {
+	s := newTestServices(t)
+	s.grantRole("erin", "viewer")
+	tender := s.publishedTender()
+	draft := s.createBid(tender.Id)
+	published := s.publishedBid(tender.Id)
+
+	for username, visible := range map[string][]string{
+		"bob":   {draft.Id, published.Id},
+		"alice": {published.Id},
+		"erin":  {published.Id},
+		"carol": {},
+	} {
+		bids, total, err := s.bids.ListByTender(username, tender.Id, nil, repository.Page{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != len(visible) || len(bids) != len(visible) {
+			t.Errorf("%s: в списке %d предложений, ожидалось %d", username, total, len(visible))
+		}
+		for _, bid := range []*repository.Bid{draft, published} {
+			_, err := s.bids.GetDetail(username, bid.Id)
+			if slices.Contains(visible, bid.Id) && err != nil {
+				t.Errorf("%s: предложение %s %s недоступно: %v", username, bid.Status, bid.Id, err)
+			}
+			if !slices.Contains(visible, bid.Id) && !errors.Is(err, ErrUserCannotViewBid) {
+				t.Errorf("%s: предложение %s %s доступно вне списка: %v", username, bid.Status, bid.Id, err)
+			}
+		}
+	}
+}
+
+func TestBidRejectedByOneDecision(t *testing.T) {
+	s := newTestServices(t)
+	tender := s.publishedTender()
+	bid := s.publishedBid(tender.Id)
+
+	_, err := s.bids.SubmitDecision(actor("alice"), bid.Id, "Rejected")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.bids.SubmitDecision(actor("alice"), bid.Id, "Approved")
+	if !errors.Is(err, ErrBidHasDecision) {
+		t.Fatalf("решение по отклоненному предложению: %v", err)
+	}
+	_, err = s.bids.Edit(actor("bob"), bid.Id, BidEdit{}, "")
+	if !errors.Is(err, ErrBidReadOnly) {
+		t.Fatalf("правка отклоненного предложения: %v", err)
+	}
+}
+
+func TestBidRoleChecks(t *testing.T) {
+	s := newTestServices(t)
+	s.grantRole("carol", "viewer")
+	s.grantRole("erin", "tender_manager")
+	tender := s.publishedTender()
+	bid := s.publishedBid(tender.Id)
+
+	_, err := s.bids.SubmitDecision(actor("carol"), bid.Id, "Approved")
+	if !errors.Is(err, ErrUserNotResponsible) {
+		t.Fatalf("решение от viewer: %v", err)
+	}
+	_, err = s.bids.SubmitDecision(actor("erin"), bid.Id, "Approved")
+	if !errors.Is(err, ErrUserNotResponsible) {
+		t.Fatalf("решение от tender_manager: %v", err)
+	}
+	_, err = s.bids.ChangeStatus(actor("alice"), bid.Id, "Canceled", "")
+	if !errors.Is(err, ErrUserNotAuthor) {
+		t.Fatalf("отмена чужого предложения: %v", err)
+	}
+}
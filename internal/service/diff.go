@@ -0,0 +1,80 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Изменение поля между двумя версиями
+type FieldChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+type VersionDiff struct {
+	From    int
+	To      int
+	Changes []FieldChange
+	// Построчное сравнение описаний в формате unified diff, пусто если описания совпадают
+	DescriptionDiff string
+}
+
+type diffField struct {
+	name     string
+	from, to string
+}
+
+func diffFields(fields ...diffField) []FieldChange {
+	changes := []FieldChange{}
+	for _, field := range fields {
+		if field.from != field.to {
+			changes = append(changes, FieldChange{Field: field.name, From: field.from, To: field.to})
+		}
+	}
+	return changes
+}
+
+// Unified diff одним блоком: описания короткие, поэтому контекст не обрезается
+func unifiedDiff(fromLabel, toLabel, from, to string) string {
+	if from == to {
+		return ""
+	}
+	fromLines := strings.Split(from, "\n")
+	toLines := strings.Split(to, "\n")
+
+	// lcs[i][j] - длина наибольшей общей подпоследовательности fromLines[i:] и toLines[j:]
+	lcs := make([][]int, len(fromLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(toLines)+1)
+	}
+	for i := len(fromLines) - 1; i >= 0; i-- {
+		for j := len(toLines) - 1; j >= 0; j-- {
+			if fromLines[i] == toLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff strings.Builder
+	fmt.Fprintf(&diff, "--- %s\n+++ %s\n", fromLabel, toLabel)
+	fmt.Fprintf(&diff, "@@ -1,%d +1,%d @@\n", len(fromLines), len(toLines))
+	i, j := 0, 0
+	for i < len(fromLines) || j < len(toLines) {
+		switch {
+		case i < len(fromLines) && j < len(toLines) && fromLines[i] == toLines[j]:
+			diff.WriteString(" " + fromLines[i] + "\n")
+			i++
+			j++
+		case i < len(fromLines) && (j == len(toLines) || lcs[i+1][j] >= lcs[i][j+1]):
+			diff.WriteString("-" + fromLines[i] + "\n")
+			i++
+		default:
+			diff.WriteString("+" + toLines[j] + "\n")
+			j++
+		}
+	}
+	return diff.String()
+}
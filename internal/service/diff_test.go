@@ -0,0 +1,83 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTenderDiff(t *testing.T) {
+	s := newTestServices(t)
+	tender := s.createTender()
+	name, description := "Тендер 2", "Описание\nСроки"
+	_, err := s.tenders.Edit(actor("alice"), tender.Id, TenderEdit{Name: &name, Description: &description}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := s.tenders.Diff("alice", tender.Id, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.From != 1 || diff.To != 2 {
+		t.Fatalf("сравнены версии %d и %d, ожидались 1 и 2", diff.From, diff.To)
+	}
+	// serviceType не менялся и в изменения не попадает
+	wantChanges := []FieldChange{
+		{Field: "name", From: "Тендер", To: "Тендер 2"},
+		{Field: "description", From: "Описание", To: "Описание\nСроки"},
+	}
+	if !reflect.DeepEqual(diff.Changes, wantChanges) {
+		t.Errorf("изменения %+v, ожидались %+v", diff.Changes, wantChanges)
+	}
+	wantDiff := "--- version 1\n+++ version 2\n@@ -1,1 +1,2 @@\n Описание\n+Сроки\n"
+	if diff.DescriptionDiff != wantDiff {
+		t.Errorf("сравнение описаний:\n%s\nожидалось:\n%s", diff.DescriptionDiff, wantDiff)
+	}
+
+	same, err := s.tenders.Diff("alice", tender.Id, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(same.Changes) != 0 || same.DescriptionDiff != "" {
+		t.Errorf("одинаковые версии различаются: %+v", same)
+	}
+}
+
+func TestBidDiff(t *testing.T) {
+	s := newTestServices(t)
+	bid := s.createBid(s.publishedTender().Id)
+	name := "Предложение 2"
+	_, err := s.bids.Edit(actor("bob"), bid.Id, BidEdit{Name: &name}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := s.bids.Diff("bob", bid.Id, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantChanges := []FieldChange{{Field: "name", From: "Предложение", To: "Предложение 2"}}
+	if !reflect.DeepEqual(diff.Changes, wantChanges) {
+		t.Errorf("изменения %+v, ожидались %+v", diff.Changes, wantChanges)
+	}
+	if diff.DescriptionDiff != "" {
+		t.Errorf("описание не менялось, а сравнение описаний:\n%s", diff.DescriptionDiff)
+	}
+
+	same, err := s.bids.Diff("bob", bid.Id, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(same.Changes) != 0 || same.DescriptionDiff != "" {
+		t.Errorf("одинаковые версии различаются: %+v", same)
+	}
+}
+
+// Удаленные строки идут перед добавленными, общие строки остаются контекстом
+func TestUnifiedDiff(t *testing.T) {
+	got := unifiedDiff("a", "b", "один\nдва\nтри", "один\nчетыре\nтри")
+	want := "--- a\n+++ b\n@@ -1,3 +1,3 @@\n один\n-два\n+четыре\n три\n"
+	if got != want {
+		t.Errorf("сравнение:\n%s\nожидалось:\n%s", got, want)
+	}
+}
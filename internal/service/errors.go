@@ -0,0 +1,67 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+
+	"avitoTask/internal/repository"
+)
+
+// Ошибки бизнес-правил. Обработчики сопоставляют их с ответами своего транспорта
+var (
+	ErrOrganizationNotFound = errors.New("организация не существует или некорректна")
+	ErrAuthorNotFound       = errors.New("автор не существует")
+	ErrTenderNotFound       = errors.New("тендер не существует")
+	ErrBidNotFound          = errors.New("предложение не существует")
+	ErrAuthorBidNotFound    = errors.New("у автора нет предложений по тендеру")
+	ErrVersionNotFound      = errors.New("версия не существует")
+	ErrInvalidVersion       = errors.New("версия должна быть меньше текущей")
+	ErrVersionBroken        = errors.New("снимок версии поврежден")
+	ErrEmployeeNotFound     = errors.New("сотрудник не существует")
+	ErrRoleNotFound         = errors.New("у сотрудника нет роли в организации")
+	ErrWebhookNotFound      = errors.New("подписка не существует")
+	ErrDeliveryNotFound     = errors.New("доставка не существует")
+	ErrInvalidWebhookUrl    = errors.New("некорректный адрес подписки")
+	ErrWebhookTarget        = errors.New("адрес подписки указывает на локальную или частную сеть")
+
+	ErrUserNotResponsible       = errors.New("пользователь не имеет прав в организации")
+	ErrUserNotAuthor            = errors.New("пользователь не является автором предложения или ответственным за организацию")
+	ErrUserCannotViewTender     = errors.New("пользователь не может просматривать тендер")
+	ErrUserCannotViewBid        = errors.New("пользователь не может просматривать предложение")
+	ErrUserCannotManageRoles    = errors.New("пользователь не может управлять ролями организации")
+	ErrUserCannotManageQuorum   = errors.New("пользователь не может управлять кворумом организации")
+	ErrUserCannotManageWebhooks = errors.New("пользователь не может управлять подписками организации")
+	ErrUserCannotViewAudit      = errors.New("пользователь не может просматривать журнал аудита организации")
+
+	ErrInvalidTenderStatusTransition = errors.New("недопустимый переход статуса тендера")
+	ErrInvalidBidStatusTransition    = errors.New("недопустимый переход статуса предложения")
+	ErrTenderNotPublished            = errors.New("тендер не опубликован")
+	ErrBidReadOnly                   = errors.New("предложение недоступно для изменения")
+	ErrBidHasDecision                = errors.New("по предложению уже принято решение")
+	ErrUserHasDecision               = errors.New("пользователь уже принял решение по предложению")
+)
+
+// Нарушение бизнес-правил, обнаруженное хранилищем
+type StateConflictError struct {
+	Reason string
+}
+
+func (e *StateConflictError) Error() string {
+	return e.Reason
+}
+
+// Репозитории и проверки прав сообщают об отсутствии записи или прав через sql.ErrNoRows
+func replaceNoRows(err, target error) error {
+	if err == sql.ErrNoRows {
+		return target
+	}
+	return err
+}
+
+func replaceConflict(err error) error {
+	var conflictErr *repository.ConflictError
+	if errors.As(err, &conflictErr) {
+		return &StateConflictError{Reason: conflictErr.Reason}
+	}
+	return err
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	validator "avitoTask/internal"
+	"avitoTask/internal/audit"
+	"avitoTask/internal/auth"
+	"avitoTask/internal/repository"
+	"avitoTask/internal/repository/memory"
+	"avitoTask/internal/webhook"
+)
+
+// Секрет подписи токенов в проверках, не короче auth.MinSecretLength
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+const (
+	testOrganizationId = "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"
+	// alice - владелец организации, bob - автор предложений без ролей в организации,
+	// carol и erin получают роли в проверках
+	testAliceId = "11111111-1111-1111-1111-111111111111"
+	testBobId   = "22222222-2222-2222-2222-222222222222"
+	testCarolId = "33333333-3333-3333-3333-333333333333"
+	testErinId  = "55555555-5555-5555-5555-555555555555"
+)
+
+// Уведомления о новых событиях outbox в проверках сервисов не нужны
+type noopNotifier struct{}
+
+func (noopNotifier) Notify() {}
+
+type testServices struct {
+	t             *testing.T
+	store         *repository.Store
+	tenders       *TenderService
+	bids          *BidService
+	organizations *OrganizationService
+	webhooks      *WebhookService
+	auditLog      *AuditService
+}
+
+func newTestServices(t *testing.T) *testServices {
+	store := memory.NewStore(&memory.Seed{
+		Employees: []memory.Employee{
+			{Id: testAliceId, Username: "alice"},
+			{Id: testBobId, Username: "bob"},
+			{Id: testCarolId, Username: "carol"},
+			{Id: testErinId, Username: "erin"},
+		},
+		Organizations: []memory.Organization{{Id: testOrganizationId, Name: "Org"}},
+		OrganizationResponsibles: []memory.OrganizationResponsible{
+			{OrganizationId: testOrganizationId, UserId: testAliceId},
+		},
+	})
+	validator := validator.NewValidator(store)
+	authorization := auth.NewAuth(store, testSecret)
+	// Адреса подписок в проверках не разрешаются
+	dispatcher := webhook.NewDispatcher(store, webhook.Config{AllowPrivateTargets: true})
+	return &testServices{
+		t:             t,
+		store:         store,
+		tenders:       NewTenderService(store, validator, authorization, noopNotifier{}),
+		bids:          NewBidService(store, validator, authorization, noopNotifier{}),
+		organizations: NewOrganizationService(store, validator, authorization),
+		webhooks:      NewWebhookService(store, validator, authorization, dispatcher),
+		auditLog:      NewAuditService(store, validator, authorization),
+	}
+}
+
+// Журнал, запись в который всегда завершается ошибкой
+type failingAudit struct {
+	repository.AuditRepository
+}
+
+var errAuditUnavailable = errors.New("журнал недоступен")
+
+func (failingAudit) Create(*repository.AuditEntry) error {
+	return errAuditUnavailable
+}
+
+// Транзакции хранилища, в которых журнал заменен на failingAudit. Сервисы получают репозитории
+// через интерфейсы, поэтому отказ отдельного репозитория проверяется без изменения сервисов
+type failingAuditTransactor struct {
+	repository.Transactor
+}
+
+func (t failingAuditTransactor) InTx(fn func(tx *repository.Store) error) error {
+	return t.Transactor.InTx(func(tx *repository.Store) error {
+		tx.Audit = failingAudit{tx.Audit}
+		return fn(tx)
+	})
+}
+
+// Дальнейшие записи в журнал завершаются ошибкой
+func (s *testServices) failAudit() {
+	s.store.Transactor = failingAuditTransactor{s.store.Transactor}
+}
+
+func actor(username string) audit.Actor {
+	return audit.Actor{Username: username}
+}
+
+// Выдает сотруднику роль в организации testOrganizationId
+func (s *testServices) grantRole(username, role string) {
+	s.t.Helper()
+	_, err := s.store.Organizations.GrantRole(testOrganizationId, username, role, "alice")
+	if err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+// Тендер организации testOrganizationId в статусе Created
+func (s *testServices) createTender() *repository.Tender {
+	s.t.Helper()
+	tender := &repository.Tender{
+		Name:           "Тендер",
+		Description:    "Описание",
+		ServiceType:    "Delivery",
+		Status:         "Created",
+		OrganizationId: testOrganizationId,
+		Version:        1,
+		CreatedAt:      time.Now().Format(time.RFC3339),
+	}
+	err := s.tenders.Create(actor("alice"), tender)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return tender
+}
+
+func (s *testServices) publishedTender() *repository.Tender {
+	s.t.Helper()
+	tender := s.createTender()
+	published, err := s.tenders.ChangeStatus(actor("alice"), tender.Id, "Published", "")
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return published
+}
+
+// Предложение bob в статусе Created
+func (s *testServices) createBid(tenderId string) *repository.Bid {
+	s.t.Helper()
+	bid := &repository.Bid{
+		Name:        "Предложение",
+		Description: "Описание",
+		Status:      "Created",
+		TenderId:    tenderId,
+		AuthorType:  "User",
+		AuthorId:    testBobId,
+		Version:     1,
+		CreatedAt:   time.Now().Format(time.RFC3339),
+	}
+	err := s.bids.Create(actor("bob"), bid)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return bid
+}
+
+func (s *testServices) publishedBid(tenderId string) *repository.Bid {
+	s.t.Helper()
+	bid := s.createBid(tenderId)
+	published, err := s.bids.ChangeStatus(actor("bob"), bid.Id, "Published", "")
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return published
+}
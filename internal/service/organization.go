@@ -0,0 +1,166 @@
+package service
+
+import (
+	"database/sql"
+
+	validator "avitoTask/internal"
+	"avitoTask/internal/audit"
+	"avitoTask/internal/auth"
+	"avitoTask/internal/repository"
+)
+
+// Кворум организации, IsDefault - для организации не задан свой кворум. Effective - кворум, по которому
+// принимаются решения: не больше числа сотрудников, которые могут согласовывать предложения
+type QuorumDetail struct {
+	Quorum    int
+	IsDefault bool
+	Effective int
+}
+
+type OrganizationService struct {
+	store     *repository.Store
+	validator *validator.Validator
+	auth      *auth.Auth
+}
+
+func NewOrganizationService(store *repository.Store, validator *validator.Validator, auth *auth.Auth) *OrganizationService {
+	return &OrganizationService{store: store, validator: validator, auth: auth}
+}
+
+func (s *OrganizationService) ListRoles(username, organizationId string) ([]repository.OrganizationRole, error) {
+	err := s.authorize(username, organizationId, auth.ActionManageRoles, ErrUserCannotManageRoles)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.Organizations.ListRoles(organizationId)
+}
+
+func (s *OrganizationService) GrantRole(actor audit.Actor, organizationId, employeeUsername, role string) (*repository.OrganizationRole, error) {
+	err := s.authorizeRoleChange(actor.Username, organizationId, employeeUsername)
+	if err != nil {
+		return nil, err
+	}
+
+	var grantedRole *repository.OrganizationRole
+	err = s.store.InTx(func(tx *repository.Store) error {
+		var err error
+		grantedRole, err = tx.Organizations.GrantRole(organizationId, employeeUsername, role, actor.Username)
+		if err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, audit.Change{
+			Action:         audit.ActionGrantRole,
+			EntityType:     audit.EntityOrganization,
+			EntityId:       organizationId,
+			OrganizationId: organizationId,
+			After:          grantedRole,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grantedRole, nil
+}
+
+func (s *OrganizationService) RevokeRole(actor audit.Actor, organizationId, employeeUsername, role string) error {
+	err := s.authorizeRoleChange(actor.Username, organizationId, employeeUsername)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.InTx(func(tx *repository.Store) error {
+		err := tx.Organizations.RevokeRole(organizationId, employeeUsername, role)
+		if err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, audit.Change{
+			Action:         audit.ActionRevokeRole,
+			EntityType:     audit.EntityOrganization,
+			EntityId:       organizationId,
+			OrganizationId: organizationId,
+			Before:         &repository.OrganizationRole{Username: employeeUsername, Role: role},
+		})
+	})
+	return replaceNoRows(err, ErrRoleNotFound)
+}
+
+func (s *OrganizationService) GetQuorum(username, organizationId string) (*QuorumDetail, error) {
+	err := s.authorize(username, organizationId, auth.ActionManageQuorum, ErrUserCannotManageQuorum)
+	if err != nil {
+		return nil, err
+	}
+
+	return getOrganizationQuorum(s.store, organizationId)
+}
+
+func (s *OrganizationService) SetQuorum(actor audit.Actor, organizationId string, quorum int) (*QuorumDetail, error) {
+	err := s.authorize(actor.Username, organizationId, auth.ActionManageQuorum, ErrUserCannotManageQuorum)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.InTx(func(tx *repository.Store) error {
+		change := audit.Change{
+			Action:         audit.ActionSetQuorum,
+			EntityType:     audit.EntityOrganization,
+			EntityId:       organizationId,
+			OrganizationId: organizationId,
+			After:          &repository.OrganizationQuorum{OrganizationId: organizationId, Quorum: quorum},
+		}
+		oldQuorum, err := tx.Organizations.GetQuorum(organizationId)
+		if err == nil {
+			change.Before = &repository.OrganizationQuorum{OrganizationId: organizationId, Quorum: oldQuorum}
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+		err = tx.Organizations.SetQuorum(organizationId, quorum)
+		if err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return getOrganizationQuorum(s.store, organizationId)
+}
+
+func getOrganizationQuorum(store *repository.Store, organizationId string) (*QuorumDetail, error) {
+	detail := &QuorumDetail{Quorum: Quorum, IsDefault: true}
+	quorum, err := store.Organizations.GetQuorum(organizationId)
+	if err == nil {
+		detail.Quorum, detail.IsDefault = quorum, false
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+	approversCnt, err := store.Organizations.CountMembersWithRoles(organizationId,
+		auth.RolesWithPermission(auth.ActionApproveBid))
+	if err != nil {
+		return nil, err
+	}
+	detail.Effective = min(detail.Quorum, approversCnt)
+	return detail, nil
+}
+
+// Проверяет, что организация существует и пользователь может выполнять в ней action, иначе возвращает denied
+func (s *OrganizationService) authorize(username, organizationId string, action auth.Action, denied error) error {
+	err := s.validator.CheckOrganizationExists(organizationId)
+	if err != nil {
+		return replaceNoRows(err, ErrOrganizationNotFound)
+	}
+	err = s.auth.Authorize(username, organizationId, action)
+	return replaceNoRows(err, denied)
+}
+
+func (s *OrganizationService) authorizeRoleChange(username, organizationId, employeeUsername string) error {
+	err := s.validator.CheckOrganizationExists(organizationId)
+	if err != nil {
+		return replaceNoRows(err, ErrOrganizationNotFound)
+	}
+	err = s.validator.CheckUserExists(employeeUsername)
+	if err != nil {
+		return replaceNoRows(err, ErrEmployeeNotFound)
+	}
+	err = s.auth.Authorize(username, organizationId, auth.ActionManageRoles)
+	return replaceNoRows(err, ErrUserCannotManageRoles)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"avitoTask/internal/repository"
+)
+
+const testUnknownOrganizationId = "cccccccc-cccc-cccc-cccc-cccccccccccc"
+
+func TestOrganizationRoles(t *testing.T) {
+	s := newTestServices(t)
+
+	if _, err := s.organizations.ListRoles("erin", testOrganizationId); !errors.Is(err, ErrUserCannotManageRoles) {
+		t.Errorf("сотрудник без ролей получил роли организации, ошибка %v", err)
+	}
+	if _, err := s.organizations.ListRoles("alice", testUnknownOrganizationId); !errors.Is(err, ErrOrganizationNotFound) {
+		t.Errorf("роли несуществующей организации, ошибка %v", err)
+	}
+	if _, err := s.organizations.GrantRole(actor("alice"), testOrganizationId, "nobody", "approver"); !errors.Is(err, ErrEmployeeNotFound) {
+		t.Errorf("роль выдана несуществующему сотруднику, ошибка %v", err)
+	}
+	if _, err := s.organizations.GrantRole(actor("erin"), testOrganizationId, "carol", "approver"); !errors.Is(err, ErrUserCannotManageRoles) {
+		t.Errorf("сотрудник без ролей выдал роль, ошибка %v", err)
+	}
+
+	granted, err := s.organizations.GrantRole(actor("alice"), testOrganizationId, "carol", "approver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if granted.Username != "carol" || granted.Role != "approver" {
+		t.Fatalf("выдана роль %+v", granted)
+	}
+	err = s.organizations.RevokeRole(actor("alice"), testOrganizationId, "carol", "approver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.organizations.RevokeRole(actor("alice"), testOrganizationId, "carol", "approver")
+	if !errors.Is(err, ErrRoleNotFound) {
+		t.Errorf("повторный отзыв роли, ошибка %v", err)
+	}
+
+	entries, err := s.store.Audit.List(repository.AuditFilter{OrganizationId: testOrganizationId}, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("в журнале %d записей, ожидались выдача и отзыв роли", len(entries))
+	}
+}
+
+func TestOrganizationQuorumService(t *testing.T) {
+	s := newTestServices(t)
+
+	quorum, err := s.organizations.GetQuorum("alice", testOrganizationId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quorum.Quorum != Quorum || !quorum.IsDefault {
+		t.Fatalf("кворум %+v, ожидался кворум по умолчанию", quorum)
+	}
+	if quorum.Effective != 1 {
+		t.Fatalf("действующий кворум %d, ожидался 1: согласовывать может только alice", quorum.Effective)
+	}
+	if _, err := s.organizations.SetQuorum(actor("erin"), testOrganizationId, 1); !errors.Is(err, ErrUserCannotManageQuorum) {
+		t.Errorf("сотрудник без ролей изменил кворум, ошибка %v", err)
+	}
+
+	_, err = s.organizations.SetQuorum(actor("alice"), testOrganizationId, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quorum, err = s.organizations.GetQuorum("alice", testOrganizationId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quorum.Quorum != 2 || quorum.IsDefault || quorum.Effective != 1 {
+		t.Fatalf("кворум %+v, ожидался 2 с действующим 1", quorum)
+	}
+
+	s.grantRole("carol", "approver")
+	s.grantRole("erin", "approver")
+	quorum, err = s.organizations.GetQuorum("alice", testOrganizationId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quorum.Effective != 2 {
+		t.Fatalf("действующий кворум %d при трех согласующих, ожидался 2", quorum.Effective)
+	}
+}
+
+func TestAuditListRequiresViewAudit(t *testing.T) {
+	s := newTestServices(t)
+	s.createTender()
+	filter := repository.AuditFilter{OrganizationId: testOrganizationId}
+
+	if _, err := s.auditLog.List("erin", filter, 10, 0); !errors.Is(err, ErrUserCannotViewAudit) {
+		t.Errorf("сотрудник без ролей получил журнал, ошибка %v", err)
+	}
+	entries, err := s.auditLog.List("alice", filter, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("в журнале %d записей, ожидалось создание тендера", len(entries))
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"slices"
+
+	"avitoTask/internal/auth"
+	"avitoTask/internal/repository"
+)
+
+// Виды записей, по которым выполняется поиск
+const (
+	SearchTypeTender = "tender"
+	SearchTypeBid    = "bid"
+)
+
+type SearchResult struct {
+	Tenders []repository.FoundTender
+	Bids    []repository.FoundBid
+}
+
+type SearchService struct {
+	store *repository.Store
+}
+
+func NewSearchService(store *repository.Store) *SearchService {
+	return &SearchService{store: store}
+}
+
+// Полнотекстовый поиск по тендерам и предложениям, которые пользователь может просматривать.
+// types ограничивает виды записей, пустой - поиск по всем
+func (s *SearchService) Search(username, query string, types []string, limit, offset int) (*SearchResult, error) {
+	result := &SearchResult{Tenders: []repository.FoundTender{}, Bids: []repository.FoundBid{}}
+
+	var err error
+	if len(types) == 0 || slices.Contains(types, SearchTypeTender) {
+		result.Tenders, err = s.store.Tenders.Search(query, repository.TenderFilter{
+			ViewerUsername: username,
+			ViewerRoles:    auth.RolesWithPermission(auth.ActionViewTender),
+		}, limit, offset)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(types) == 0 || slices.Contains(types, SearchTypeBid) {
+		result.Bids, err = s.store.Bids.Search(query, repository.BidFilter{
+			ViewerUsername: username,
+			ViewerRoles:    auth.RolesWithPermission(auth.ActionViewBid),
+		}, limit, offset)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
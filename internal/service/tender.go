@@ -0,0 +1,421 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	validator "avitoTask/internal"
+	"avitoTask/internal/audit"
+	"avitoTask/internal/auth"
+	"avitoTask/internal/events"
+	"avitoTask/internal/metrics"
+	"avitoTask/internal/outbox"
+	"avitoTask/internal/repository"
+)
+
+// Допустимые переходы статусов тендера, дублируются в таблице tender_status_transition
+var TenderStatusTransitions map[string][]string = map[string][]string{
+	"Created":   {"Published", "Closed"},
+	"Published": {"Closed"},
+	"Closed":    {},
+}
+
+func CheckTenderStatusTransition(from, to string) bool {
+	return from == to || slices.Contains(TenderStatusTransitions[from], to)
+}
+
+// Событие о смене статуса тендера tender на status
+func newTenderEvent(eventType string, tender *repository.Tender, status string) events.Event {
+	event := events.NewEvent(eventType, tender.Id)
+	event.OrganizationId = tender.OrganizationId
+	event.Status = status
+	event.PreviousStatus = tender.Status
+	return event
+}
+
+// Изменяемые поля тендера, nil - поле не меняется
+type TenderEdit struct {
+	Name        *string
+	Description *string
+	ServiceType *string
+}
+
+// Итог выбора предложения по тендеру
+const (
+	TenderOutcomePending  = "Pending"
+	TenderOutcomeApproved = "Approved"
+	TenderOutcomeClosed   = "Closed"
+)
+
+// Тендер с организацией, количеством предложений по статусам и итогом выбора предложения
+type TenderDetail struct {
+	Tender       *repository.Tender
+	Organization *repository.Organization
+	BidCounts    map[string]int
+	Outcome      string
+	// Принятое предложение, nil - предложение не принято
+	ApprovedBid *repository.Bid
+}
+
+type TenderService struct {
+	store     *repository.Store
+	validator *validator.Validator
+	auth      *auth.Auth
+	relay     outbox.Notifier
+}
+
+func NewTenderService(store *repository.Store, validator *validator.Validator, auth *auth.Auth,
+	relay outbox.Notifier) *TenderService {
+	return &TenderService{store: store, validator: validator, auth: auth, relay: relay}
+}
+
+// Страница тендеров по фильтру, которые пользователь может просматривать, и их общее количество
+func (s *TenderService) List(username string, filter repository.TenderFilter, page repository.Page) ([]repository.Tender, int, error) {
+	filter.ViewerUsername = username
+	filter.ViewerRoles = auth.RolesWithPermission(auth.ActionViewTender)
+	tenders, err := s.store.Tenders.List(filter, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.store.Tenders.Count(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tenders, total, nil
+}
+
+// Тендеры организаций, в которых пользователь может создавать и изменять тендеры
+func (s *TenderService) ListByMember(username string, page repository.Page) ([]repository.Tender, int, error) {
+	roles := auth.RolesWithPermission(auth.ActionManageTender)
+	tenders, err := s.store.Tenders.ListByMember(username, roles, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.store.Tenders.CountByMember(username, roles)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tenders, total, nil
+}
+
+func (s *TenderService) GetStatus(username, tenderId string) (string, error) {
+	tender, err := s.getVisible(username, tenderId)
+	if err != nil {
+		return "", err
+	}
+	return tender.Status, nil
+}
+
+func (s *TenderService) GetDetail(username, tenderId string) (*TenderDetail, error) {
+	tender, err := s.getVisible(username, tenderId)
+	if err != nil {
+		return nil, err
+	}
+
+	organization, err := s.store.Organizations.Get(tender.OrganizationId)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrOrganizationNotFound)
+	}
+
+	// Считаются только предложения, которые пользователь видит в списке предложений тендера
+	statusCounts, err := s.store.Bids.CountByStatus(tender.Id, repository.BidFilter{
+		ViewerUsername: username,
+		ViewerRoles:    auth.RolesWithPermission(auth.ActionViewBid),
+	})
+	if err != nil {
+		return nil, err
+	}
+	bidCounts := map[string]int{}
+	for status := range BidStatusTransitions {
+		bidCounts[status] = statusCounts[status]
+	}
+
+	detail := &TenderDetail{Tender: tender, Organization: organization, BidCounts: bidCounts, Outcome: TenderOutcomePending}
+	approvedBid, err := s.store.Bids.GetApproved(tender.Id)
+	switch {
+	case err == nil:
+		detail.Outcome = TenderOutcomeApproved
+		detail.ApprovedBid = approvedBid
+	case err != sql.ErrNoRows:
+		return nil, err
+	case tender.Status == "Closed":
+		detail.Outcome = TenderOutcomeClosed
+	}
+
+	return detail, nil
+}
+
+// Версии тендера из истории и последней идет текущая версия
+func (s *TenderService) ListVersions(username, tenderId string) ([]repository.TenderVersion, error) {
+	tender, err := s.getVisible(username, tenderId)
+	if err != nil {
+		return nil, err
+	}
+
+	tenderVersions, err := s.store.Tenders.ListVersions(tender.Id)
+	if err != nil {
+		return nil, err
+	}
+	return append(tenderVersions, *currentTenderVersion(tender)), nil
+}
+
+func (s *TenderService) GetVersion(username, tenderId string, version int) (*repository.TenderVersion, error) {
+	tender, err := s.getVisible(username, tenderId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getVersion(tender, version)
+}
+
+// Сравнение версии from с версией to, при to = 0 сравнивается с текущей версией
+func (s *TenderService) Diff(username, tenderId string, from, to int) (*VersionDiff, error) {
+	tender, err := s.getVisible(username, tenderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if to == 0 {
+		to = tender.Version
+	}
+	fromVersion, err := s.getVersion(tender, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := s.getVersion(tender, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VersionDiff{
+		From: from,
+		To:   to,
+		Changes: diffFields(
+			diffField{"name", fromVersion.Name, toVersion.Name},
+			diffField{"description", fromVersion.Description, toVersion.Description},
+			diffField{"serviceType", fromVersion.ServiceType, toVersion.ServiceType},
+		),
+		DescriptionDiff: unifiedDiff(fmt.Sprintf("version %d", from), fmt.Sprintf("version %d", to),
+			fromVersion.Description, toVersion.Description),
+	}, nil
+}
+
+// Создает тендер в статусе Created с первой версией и текущим временем создания, переданные значения
+// не учитываются: статус меняется только через ChangeStatus с проверкой переходов, а по времени создания
+// сортируются и фильтруются списки
+func (s *TenderService) Create(actor audit.Actor, tender *repository.Tender) error {
+	tender.Status = "Created"
+	tender.Version = 1
+	tender.CreatedAt = time.Now().UTC().Format(time.RFC3339Nano)
+
+	err := s.validator.CheckOrganizationExists(tender.OrganizationId)
+	if err != nil {
+		return replaceNoRows(err, ErrOrganizationNotFound)
+	}
+
+	err = s.auth.Authorize(actor.Username, tender.OrganizationId, auth.ActionManageTender)
+	if err != nil {
+		return replaceNoRows(err, ErrUserNotResponsible)
+	}
+
+	err = s.store.InTx(func(tx *repository.Store) error {
+		err := tx.Tenders.Create(tender)
+		if err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, audit.Change{
+			Action:         audit.ActionCreate,
+			EntityType:     audit.EntityTender,
+			EntityId:       tender.Id,
+			OrganizationId: tender.OrganizationId,
+			After:          tender,
+		})
+	})
+	if err != nil {
+		return replaceConflict(err)
+	}
+	metrics.TendersCreated.Inc()
+	return nil
+}
+
+func (s *TenderService) ChangeStatus(actor audit.Actor, tenderId, status, comment string) (*repository.Tender, error) {
+	tender, err := s.getManaged(actor.Username, tenderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !CheckTenderStatusTransition(tender.Status, status) {
+		return nil, ErrInvalidTenderStatusTransition
+	}
+
+	updated, err := s.update(actor, audit.ActionStatusChange, tender, func(tx *repository.Store) error {
+		err := tx.Tenders.UpdateStatus(tender.Id, status, repository.Change{
+			Username: actor.Username,
+			Kind:     repository.ChangeKindStatusChange,
+			Comment:  comment,
+		})
+		if err != nil || status == tender.Status {
+			return err
+		}
+		return outbox.Add(tx, newTenderEvent(events.TenderStatusChanged, tender, status))
+	})
+	if repository.IsConflict(err) {
+		return nil, ErrInvalidTenderStatusTransition
+	} else if err != nil {
+		return nil, err
+	}
+	s.relay.Notify()
+
+	return updated, nil
+}
+
+func (s *TenderService) Edit(actor audit.Actor, tenderId string, edit TenderEdit, comment string) (*repository.Tender, error) {
+	tender, err := s.getManaged(actor.Username, tenderId)
+	if err != nil {
+		return nil, err
+	}
+
+	edited := *tender
+	if edit.Name != nil {
+		edited.Name = *edit.Name
+	}
+	if edit.Description != nil {
+		edited.Description = *edit.Description
+	}
+	if edit.ServiceType != nil {
+		edited.ServiceType = *edit.ServiceType
+	}
+	updated, err := s.update(actor, audit.ActionEdit, tender, func(tx *repository.Store) error {
+		return tx.Tenders.Update(&edited, repository.Change{
+			Username: actor.Username,
+			Kind:     repository.ChangeKindEdit,
+			Comment:  comment,
+		})
+	})
+	if err != nil {
+		return nil, replaceConflict(err)
+	}
+
+	return updated, nil
+}
+
+// Откат создает новую версию с названием, описанием и видом услуги из указанной версии
+func (s *TenderService) Rollback(actor audit.Actor, tenderId string, version int, comment string) (*repository.Tender, error) {
+	tender, err := s.getManaged(actor.Username, tenderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if version >= tender.Version {
+		return nil, ErrInvalidVersion
+	}
+
+	tenderVersion, err := s.store.Tenders.GetVersion(tender.Id, version)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrVersionNotFound)
+	}
+	if tenderVersion.Broken {
+		return nil, ErrVersionBroken
+	}
+
+	rolledBack := *tender
+	rolledBack.Name = tenderVersion.Name
+	rolledBack.Description = tenderVersion.Description
+	rolledBack.ServiceType = tenderVersion.ServiceType
+	updated, err := s.update(actor, audit.ActionRollback, tender, func(tx *repository.Store) error {
+		return tx.Tenders.Update(&rolledBack, repository.Change{
+			Username: actor.Username,
+			Kind:     repository.ChangeKindRollback,
+			Comment:  comment,
+		})
+	})
+	if err != nil {
+		return nil, replaceConflict(err)
+	}
+
+	return updated, nil
+}
+
+// Выполняет изменение тендера в транзакции вместе с записью журнала аудита, возвращает тендер после изменения
+func (s *TenderService) update(actor audit.Actor, action string, before *repository.Tender,
+	change func(tx *repository.Store) error) (*repository.Tender, error) {
+	var after *repository.Tender
+	err := s.store.InTx(func(tx *repository.Store) error {
+		err := change(tx)
+		if err != nil {
+			return err
+		}
+		after, err = tx.Tenders.Get(before.Id)
+		if err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, audit.Change{
+			Action:         action,
+			EntityType:     audit.EntityTender,
+			EntityId:       before.Id,
+			OrganizationId: before.OrganizationId,
+			Before:         before,
+			After:          after,
+		})
+	})
+	return after, err
+}
+
+func currentTenderVersion(tender *repository.Tender) *repository.TenderVersion {
+	return &repository.TenderVersion{
+		TenderId:       tender.Id,
+		Version:        tender.Version,
+		Name:           tender.Name,
+		Description:    tender.Description,
+		ServiceType:    tender.ServiceType,
+		Status:         tender.Status,
+		OrganizationId: tender.OrganizationId,
+	}
+}
+
+func (s *TenderService) getVersion(tender *repository.Tender, version int) (*repository.TenderVersion, error) {
+	if version == tender.Version {
+		return currentTenderVersion(tender), nil
+	}
+	tenderVersion, err := s.store.Tenders.GetVersion(tender.Id, version)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrVersionNotFound)
+	}
+	return tenderVersion, nil
+}
+
+// Тендер, который пользователь может просматривать
+func (s *TenderService) getVisible(username, tenderId string) (*repository.Tender, error) {
+	err := s.validator.CheckTenderExists(tenderId)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrTenderNotFound)
+	}
+
+	err = s.auth.CheckUserViewTender(username, tenderId)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrUserCannotViewTender)
+	}
+
+	return s.store.Tenders.Get(tenderId)
+}
+
+// Тендер, которым пользователь может управлять
+func (s *TenderService) getManaged(username, tenderId string) (*repository.Tender, error) {
+	err := s.validator.CheckTenderExists(tenderId)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrTenderNotFound)
+	}
+
+	tender, err := s.store.Tenders.Get(tenderId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.auth.Authorize(username, tender.OrganizationId, auth.ActionManageTender)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrUserNotResponsible)
+	}
+	return tender, nil
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"avitoTask/internal/repository"
+)
+
+func TestTenderStatusTransitions(t *testing.T) {
+	s := newTestServices(t)
+	tender := s.createTender()
+
+	_, err := s.tenders.ChangeStatus(actor("alice"), tender.Id, "Closed", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range []string{"Published", "Created"} {
+		_, err = s.tenders.ChangeStatus(actor("alice"), tender.Id, status, "")
+		if !errors.Is(err, ErrInvalidTenderStatusTransition) {
+			t.Errorf("переход Closed -> %s: %v", status, err)
+		}
+	}
+
+	published := s.publishedTender()
+	_, err = s.tenders.ChangeStatus(actor("alice"), published.Id, "Created", "")
+	if !errors.Is(err, ErrInvalidTenderStatusTransition) {
+		t.Errorf("переход Published -> Created: %v", err)
+	}
+}
+
+// Тендер создается в статусе Created с первой версией независимо от переданных значений
+func TestTenderCreatedAsDraft(t *testing.T) {
+	s := newTestServices(t)
+	tender := &repository.Tender{
+		Name:           "Тендер",
+		ServiceType:    "Delivery",
+		Status:         "Published",
+		OrganizationId: testOrganizationId,
+		Version:        5,
+		CreatedAt:      time.Now().Format(time.RFC3339),
+	}
+	err := s.tenders.Create(actor("alice"), tender)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := s.store.Tenders.Get(tender.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != "Created" || stored.Version != 1 {
+		t.Fatalf("тендер %+v, ожидался черновик версии 1", stored)
+	}
+}
+
+func TestTenderRollback(t *testing.T) {
+	s := newTestServices(t)
+	tender := s.createTender()
+	name := "Новое название"
+	edited, err := s.tenders.Edit(actor("alice"), tender.Id, TenderEdit{Name: &name}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.Name != name || edited.Version != 2 {
+		t.Fatalf("тендер %+v, ожидалась версия 2 с новым названием", edited)
+	}
+
+	rolledBack, err := s.tenders.Rollback(actor("alice"), tender.Id, 1, "возврат")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rolledBack.Name != tender.Name || rolledBack.Version != 3 {
+		t.Fatalf("тендер %+v, ожидалась версия 3 с исходным названием", rolledBack)
+	}
+
+	versions, err := s.tenders.ListVersions("alice", tender.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 3 || versions[1].ChangeKind != "rollback" || versions[1].Comment != "возврат" {
+		t.Fatalf("история %+v, ожидалась запись об откате версии 2", versions)
+	}
+
+	_, err = s.tenders.Rollback(actor("alice"), tender.Id, 3, "")
+	if !errors.Is(err, ErrInvalidVersion) {
+		t.Fatalf("откат к текущей версии: %v", err)
+	}
+	_, err = s.tenders.Rollback(actor("alice"), tender.Id, 0, "")
+	if !errors.Is(err, ErrVersionNotFound) {
+		t.Fatalf("откат к несуществующей версии: %v", err)
+	}
+}
+
+// Ошибка записи в журнал отменяет изменение целиком: статус, версию и событие outbox
+func TestTenderChangeRolledBackWithAudit(t *testing.T) {
+	s := newTestServices(t)
+	tender := s.createTender()
+	s.failAudit()
+
+	_, err := s.tenders.ChangeStatus(actor("alice"), tender.Id, "Published", "")
+	if !errors.Is(err, errAuditUnavailable) {
+		t.Fatalf("публикация без журнала, ошибка %v", err)
+	}
+	stored, err := s.store.Tenders.Get(tender.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != "Created" || stored.Version != 1 {
+		t.Fatalf("тендер %+v, ожидался неизмененный черновик", stored)
+	}
+	pending, err := s.store.Outbox.ListPending("test", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("в outbox %d событий отмененной публикации", len(pending))
+	}
+
+	err = s.tenders.Create(actor("alice"), &repository.Tender{Name: "Тендер", ServiceType: "Delivery", OrganizationId: testOrganizationId})
+	if !errors.Is(err, errAuditUnavailable) {
+		t.Fatalf("создание без журнала, ошибка %v", err)
+	}
+	tenders, _, err := s.tenders.ListByMember("alice", repository.Page{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tenders) != 1 {
+		t.Fatalf("тендеров %d, созданный без журнала тендер не отменен", len(tenders))
+	}
+}
+
+// Смена статуса создает версию, а откат восстанавливает только содержимое и не меняет статус
+func TestTenderRollbackKeepsStatus(t *testing.T) {
+	s := newTestServices(t)
+	tender := s.publishedTender()
+	if tender.Version != 2 {
+		t.Fatalf("версия после публикации %d, ожидалась 2", tender.Version)
+	}
+	name := "Новое название"
+	_, err := s.tenders.Edit(actor("alice"), tender.Id, TenderEdit{Name: &name}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rolledBack, err := s.tenders.Rollback(actor("alice"), tender.Id, 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rolledBack.Name != tender.Name || rolledBack.Status != "Published" || rolledBack.Version != 4 {
+		t.Fatalf("тендер %+v, ожидалась опубликованная версия 4 с исходным названием", rolledBack)
+	}
+
+	// Версия 2 отличается от текущей только номером, откат к ней ничего не меняет
+	unchanged, err := s.tenders.Rollback(actor("alice"), tender.Id, 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unchanged.Version != 4 {
+		t.Fatalf("версия %d после отката без изменений, ожидалась 4", unchanged.Version)
+	}
+}
+
+// В списке своих тендеров нет тендеров организаций, где у пользователя только роль viewer
+func TestTenderListByMemberRequiresManageRole(t *testing.T) {
+	s := newTestServices(t)
+	s.createTender()
+	s.grantRole("carol", "viewer")
+
+	_, total, err := s.tenders.ListByMember("carol", repository.Page{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 {
+		t.Fatalf("наблюдателю доступно тендеров %d, ожидалось 0", total)
+	}
+
+	s.grantRole("carol", "tender_manager")
+	tenders, total, err := s.tenders.ListByMember("carol", repository.Page{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(tenders) != 1 {
+		t.Fatalf("менеджеру доступно тендеров %d, ожидался 1", total)
+	}
+}
+
+func TestBidRollbackKeepsStatus(t *testing.T) {
+	s := newTestServices(t)
+	tender := s.publishedTender()
+	bid := s.createBid(tender.Id)
+	name := "Новое название"
+	_, err := s.bids.Edit(actor("bob"), bid.Id, BidEdit{Name: &name}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.bids.ChangeStatus(actor("bob"), bid.Id, "Published", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rolledBack, err := s.bids.Rollback(actor("bob"), bid.Id, 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rolledBack.Name != bid.Name || rolledBack.Status != "Published" || rolledBack.Version != 4 {
+		t.Fatalf("предложение %+v, ожидалась опубликованная версия 4 с исходным названием", rolledBack)
+	}
+}
+
+func TestTenderRoleChecks(t *testing.T) {
+	s := newTestServices(t)
+	s.grantRole("carol", "viewer")
+	s.grantRole("erin", "tender_manager")
+	tender := s.createTender()
+
+	_, err := s.tenders.ChangeStatus(actor("carol"), tender.Id, "Published", "")
+	if !errors.Is(err, ErrUserNotResponsible) {
+		t.Fatalf("публикация от viewer: %v", err)
+	}
+	_, err = s.tenders.GetStatus("bob", tender.Id)
+	if !errors.Is(err, ErrUserCannotViewTender) {
+		t.Fatalf("просмотр неопубликованного тендера посторонним: %v", err)
+	}
+	status, err := s.tenders.GetStatus("carol", tender.Id)
+	if err != nil || status != "Created" {
+		t.Fatalf("просмотр неопубликованного тендера viewer: %s, %v", status, err)
+	}
+
+	published, err := s.tenders.ChangeStatus(actor("erin"), tender.Id, "Published", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published.Status != "Published" {
+		t.Fatalf("статус %s, ожидался Published", published.Status)
+	}
+	_, err = s.tenders.ChangeStatus(actor("erin"), tender.Id, "Created", "")
+	if !errors.Is(err, ErrInvalidTenderStatusTransition) {
+		t.Fatalf("возврат в Created: %v", err)
+	}
+}
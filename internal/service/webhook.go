@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"net/url"
+
+	validator "avitoTask/internal"
+	"avitoTask/internal/audit"
+	"avitoTask/internal/auth"
+	"avitoTask/internal/repository"
+	"avitoTask/internal/webhook"
+)
+
+type WebhookService struct {
+	store      *repository.Store
+	validator  *validator.Validator
+	auth       *auth.Auth
+	dispatcher *webhook.Dispatcher
+}
+
+func NewWebhookService(store *repository.Store, validator *validator.Validator, auth *auth.Auth,
+	dispatcher *webhook.Dispatcher) *WebhookService {
+	return &WebhookService{store: store, validator: validator, auth: auth, dispatcher: dispatcher}
+}
+
+func (s *WebhookService) List(username, organizationId string) ([]repository.Webhook, error) {
+	err := s.authorize(username, organizationId)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.Webhooks.ListByOrganization(organizationId)
+}
+
+// Создает подписку с новым секретом. Адрес проверяется после авторизации, чтобы посторонний
+// не мог заставить сервис разрешать имена; ctx ограничивает разрешение имени
+func (s *WebhookService) Create(ctx context.Context, actor audit.Actor, model *repository.Webhook) error {
+	err := s.authorize(actor.Username, model.OrganizationId)
+	if err != nil {
+		return err
+	}
+
+	webhookUrl, err := url.Parse(model.Url)
+	if err != nil || webhookUrl.Host == "" || webhookUrl.Scheme != "http" && webhookUrl.Scheme != "https" {
+		return ErrInvalidWebhookUrl
+	}
+	err = s.dispatcher.CheckTarget(ctx, webhookUrl)
+	if err == webhook.ErrTargetNotAllowed {
+		return ErrWebhookTarget
+	} else if err != nil {
+		return ErrInvalidWebhookUrl
+	}
+
+	model.Secret, err = webhook.NewSecret()
+	if err != nil {
+		return err
+	}
+	model.CreatedBy = actor.Username
+	return s.store.InTx(func(tx *repository.Store) error {
+		err := tx.Webhooks.Create(model)
+		if err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, audit.Change{
+			Action:         audit.ActionCreate,
+			EntityType:     audit.EntityWebhook,
+			EntityId:       model.Id,
+			OrganizationId: model.OrganizationId,
+			After:          model,
+		})
+	})
+}
+
+// Удаляет подписку и возвращает ее
+func (s *WebhookService) Delete(actor audit.Actor, organizationId, webhookId string) (*repository.Webhook, error) {
+	someWebhook, err := s.getManaged(actor.Username, organizationId, webhookId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.InTx(func(tx *repository.Store) error {
+		err := tx.Webhooks.Delete(webhookId)
+		if err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, audit.Change{
+			Action:         audit.ActionDelete,
+			EntityType:     audit.EntityWebhook,
+			EntityId:       webhookId,
+			OrganizationId: organizationId,
+			Before:         someWebhook,
+		})
+	})
+	if err != nil {
+		return nil, replaceNoRows(err, ErrWebhookNotFound)
+	}
+	return someWebhook, nil
+}
+
+func (s *WebhookService) ListDeliveries(username, organizationId, webhookId string, limit, offset int) ([]repository.WebhookDelivery, error) {
+	_, err := s.getManaged(username, organizationId, webhookId)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.Webhooks.ListDeliveries(webhookId, limit, offset)
+}
+
+// Создает новую доставку с содержимым доставки deliveryId
+func (s *WebhookService) Redeliver(actor audit.Actor, organizationId, webhookId, deliveryId string) (*repository.WebhookDelivery, error) {
+	someWebhook, err := s.getManaged(actor.Username, organizationId, webhookId)
+	if err != nil {
+		return nil, err
+	}
+	delivery, err := s.dispatcher.Redeliver(actor, someWebhook, deliveryId)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrDeliveryNotFound)
+	}
+	return delivery, nil
+}
+
+// Проверяет организацию и право пользователя управлять ее подписками
+func (s *WebhookService) authorize(username, organizationId string) error {
+	err := s.validator.CheckOrganizationExists(organizationId)
+	if err != nil {
+		return replaceNoRows(err, ErrOrganizationNotFound)
+	}
+	err = s.auth.Authorize(username, organizationId, auth.ActionManageWebhooks)
+	return replaceNoRows(err, ErrUserCannotManageWebhooks)
+}
+
+// Подписка организации, которой пользователь может управлять. Подписки других организаций считаются несуществующими
+func (s *WebhookService) getManaged(username, organizationId, webhookId string) (*repository.Webhook, error) {
+	err := s.authorize(username, organizationId)
+	if err != nil {
+		return nil, err
+	}
+	someWebhook, err := s.store.Webhooks.Get(webhookId)
+	if err != nil {
+		return nil, replaceNoRows(err, ErrWebhookNotFound)
+	}
+	if someWebhook.OrganizationId != organizationId {
+		return nil, ErrWebhookNotFound
+	}
+	return someWebhook, nil
+}
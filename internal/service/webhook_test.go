@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"avitoTask/internal/repository"
+)
+
+func TestWebhookService(t *testing.T) {
+	s := newTestServices(t)
+	ctx := context.Background()
+
+	model := &repository.Webhook{OrganizationId: testOrganizationId, Url: "http://127.0.0.1/hook"}
+	if err := s.webhooks.Create(ctx, actor("erin"), model); !errors.Is(err, ErrUserCannotManageWebhooks) {
+		t.Errorf("сотрудник без ролей создал подписку, ошибка %v", err)
+	}
+	invalid := &repository.Webhook{OrganizationId: testOrganizationId, Url: "ftp://127.0.0.1/hook"}
+	if err := s.webhooks.Create(ctx, actor("alice"), invalid); !errors.Is(err, ErrInvalidWebhookUrl) {
+		t.Errorf("принят адрес ftp, ошибка %v", err)
+	}
+
+	err := s.webhooks.Create(ctx, actor("alice"), model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model.Secret == "" || model.CreatedBy != "alice" {
+		t.Fatalf("подписка создана без секрета или автора: %+v", model)
+	}
+	if _, err := s.webhooks.List("erin", testOrganizationId); !errors.Is(err, ErrUserCannotManageWebhooks) {
+		t.Errorf("сотрудник без ролей получил подписки, ошибка %v", err)
+	}
+	_, err = s.webhooks.Redeliver(actor("alice"), testOrganizationId, model.Id, "dddddddd-dddd-dddd-dddd-dddddddddddd")
+	if !errors.Is(err, ErrDeliveryNotFound) {
+		t.Errorf("повторная доставка несуществующей доставки, ошибка %v", err)
+	}
+
+	deleted, err := s.webhooks.Delete(actor("alice"), testOrganizationId, model.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted.Id != model.Id {
+		t.Fatalf("удалена подписка %s, ожидалась %s", deleted.Id, model.Id)
+	}
+	if _, err := s.webhooks.Delete(actor("alice"), testOrganizationId, model.Id); !errors.Is(err, ErrWebhookNotFound) {
+		t.Errorf("повторное удаление подписки, ошибка %v", err)
+	}
+}
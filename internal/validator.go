@@ -1,38 +1,60 @@
 package validator
 
 import (
-	"github.com/jmoiron/sqlx"
+	"database/sql"
+
+	"avitoTask/internal/repository"
 )
 
-var db *sqlx.DB
+type Validator struct {
+	store *repository.Store
+}
+
+func NewValidator(store *repository.Store) *Validator {
+	return &Validator{store: store}
+}
 
-func InitValidator(conn *sqlx.DB) {
-	db = conn
+func (v *Validator) CheckUserExists(username string) error {
+	return v.store.Employees.Exists(username)
+}
+func (v *Validator) CheckOrganizationExists(organizationId string) error {
+	return v.store.Organizations.Exists(organizationId)
 }
 
-func CheckUserExists(username string) error {
-	var userExists bool
-	return db.Get(&userExists, `SELECT TRUE
-								FROM   employee
-								WHERE  username = $1`, username)
+func (v *Validator) CheckTenderExists(tenderId string) error {
+	return v.store.Tenders.Exists(tenderId)
 }
-func CheckOrganizationExists(organizationId string) error {
-	var organizationExists bool
-	return db.Get(&organizationExists, `SELECT TRUE
-								FROM   organization
-								WHERE  id = $1`, organizationId)
+
+func (v *Validator) CheckBidExists(bidId string) error {
+	return v.store.Bids.Exists(bidId)
 }
 
-func CheckTenderExists(tenderId string) error {
-	var tenderExists bool
-	return db.Get(&tenderExists, `SELECT TRUE
-								FROM   tender
-								WHERE  id = $1`, tenderId)
+func (v *Validator) CheckTenderPublished(tenderId string) error {
+	tender, err := v.store.Tenders.Get(tenderId)
+	if err != nil {
+		return err
+	}
+	if tender.Status != "Published" {
+		return sql.ErrNoRows
+	}
+	return nil
 }
 
-func CheckBidExists(bidId string) error {
-	var bidExists bool
-	return db.Get(&bidExists, `SELECT TRUE
-								FROM   bid
-								WHERE  id = $1`, bidId)
+// Предложение доступно для изменения, пока по нему не принято решение и тендер не закрыт
+func (v *Validator) CheckBidEditable(bidId string) error {
+	bid, err := v.store.Bids.Get(bidId)
+	if err != nil {
+		return err
+	}
+	if bid.Decision != nil {
+		return sql.ErrNoRows
+	}
+	tender, err := v.store.Tenders.Get(bid.TenderId)
+	if err != nil {
+		return err
+	}
+	if tender.Status == "Closed" {
+		return sql.ErrNoRows
+	}
+	return nil
 }
@@ -0,0 +1,330 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"avitoTask/internal/audit"
+	"avitoTask/internal/repository"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Заголовки запроса доставки. Подпись - HMAC-SHA256 тела запроса на секрете подписки
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Повторы доставки: пауза перед следующей попыткой удваивается, но не превышает MaxInterval
+type RetryPolicy struct {
+	MaxAttempts     int
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+}
+
+// Пауза после неудачной попытки с номером attempt, начиная с 1
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	interval := p.InitialInterval
+	for i := 1; i < attempt && interval < p.MaxInterval; i++ {
+		interval *= 2
+	}
+	return min(interval, p.MaxInterval)
+}
+
+// Количество доставок, выбираемых за один опрос
+const deliveryBatchSize = 100
+
+// Время, на которое откладывается следующая попытка выбранной доставки сверх таймаута запроса.
+// Если сервис остановится во время отправки, доставка будет выбрана снова после него
+const deliveryLease = time.Minute
+
+type Config struct {
+	Retry RetryPolicy
+	// Таймаут запроса к получателю
+	Timeout time.Duration
+	// Интервал опроса доставок, время следующей попытки которых наступило
+	PollInterval time.Duration
+	// Разрешить адреса loopback, link-local и частных сетей, например для локальной разработки
+	AllowPrivateTargets bool
+}
+
+// Доставляет события подпискам организации тендера и ведет журнал доставок. Доставки хранятся
+// со временем следующей попытки, их отправляет Run, поэтому повторы продолжаются после перезапуска
+type Dispatcher struct {
+	store  *repository.Store
+	client *http.Client
+	config Config
+	wake   chan struct{}
+}
+
+func NewDispatcher(store *repository.Store, config Config) *Dispatcher {
+	dialer := &net.Dialer{Timeout: config.Timeout}
+	if !config.AllowPrivateTargets {
+		// Адрес проверяется при каждом соединении, поэтому смена адреса в DNS после создания подписки
+		// не позволяет обратиться во внутреннюю сеть
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			return checkIp(net.ParseIP(host))
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	client := &http.Client{
+		Timeout:   config.Timeout,
+		Transport: transport,
+		// Перенаправление могло бы увести запрос на другой адрес
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return &Dispatcher{store: store, client: client, config: config, wake: make(chan struct{}, 1)}
+}
+
+var ErrTargetNotAllowed = errors.New("Адрес подписки указывает на локальную или частную сеть")
+
+// Сколько ждать разрешения имени хоста подписки
+const lookupTimeout = 5 * time.Second
+
+// Проверяет, что адрес подписки не указывает на loopback, link-local и частные сети.
+// Разрешение имени ограничено ctx и lookupTimeout
+func (d *Dispatcher) CheckTarget(ctx context.Context, target *url.URL) error {
+	if d.config.AllowPrivateTargets {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", target.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, ip := range ips {
+		err = checkIp(ip)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkIp(ip net.IP) error {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() || ip.IsInterfaceLocalMulticast() {
+		return ErrTargetNotAllowed
+	}
+	return nil
+}
+
+func NewSecret() (string, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (d *Dispatcher) Name() string {
+	return "webhook"
+}
+
+// Создает доставки подпискам организации тендера в транзакции outbox, поэтому доставка создается
+// один раз. Отправка начинается после фиксации транзакции
+func (d *Dispatcher) Handle(tx *repository.Store, event repository.OutboxEvent) (func(), error) {
+	tender, err := tx.Tenders.Get(event.TenderId)
+	if err != nil {
+		return nil, err
+	}
+	webhooks, err := tx.Webhooks.ListByOrganization(tender.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, webhook := range webhooks {
+		if len(webhook.EventTypes) > 0 && !slices.Contains(webhook.EventTypes, event.EventType) {
+			continue
+		}
+		err = tx.Webhooks.CreateDelivery(&repository.WebhookDelivery{
+			WebhookId: webhook.Id,
+			EventType: event.EventType,
+			Payload:   event.Payload,
+			Status:    repository.WebhookDeliveryPending,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return d.Notify, nil
+}
+
+// Повторная доставка создает новую запись журнала доставок с тем же телом запроса и запись
+// журнала аудита. sql.ErrNoRows - доставка не найдена или относится к другой подписке
+func (d *Dispatcher) Redeliver(actor audit.Actor, webhook *repository.Webhook, deliveryId string) (*repository.WebhookDelivery, error) {
+	original, err := d.store.Webhooks.GetDelivery(deliveryId)
+	if err != nil {
+		return nil, err
+	}
+	if original.WebhookId != webhook.Id {
+		return nil, sql.ErrNoRows
+	}
+
+	delivery := repository.WebhookDelivery{
+		WebhookId: webhook.Id,
+		EventType: original.EventType,
+		Payload:   original.Payload,
+		Status:    repository.WebhookDeliveryPending,
+	}
+	err = d.store.InTx(func(tx *repository.Store) error {
+		err := tx.Webhooks.CreateDelivery(&delivery)
+		if err != nil {
+			return err
+		}
+		return audit.Record(tx, actor, audit.Change{
+			Action:         audit.ActionRedeliver,
+			EntityType:     audit.EntityWebhook,
+			EntityId:       webhook.Id,
+			OrganizationId: webhook.OrganizationId,
+			Before:         original,
+			After:          &delivery,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	d.Notify()
+	return &delivery, nil
+}
+
+// Сообщает о новых доставках, чтобы они были отправлены без ожидания следующего опроса
+func (d *Dispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Отправляет доставки, время следующей попытки которых наступило. Опрашивает доставки с интервалом
+// PollInterval и сразу после Notify, завершается после отмены ctx и окончания начатых отправок
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		d.deliverDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		deliveries, err := d.store.Webhooks.ClaimDue(deliveryBatchSize, d.config.Timeout+deliveryLease)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.attempt(delivery)
+			}()
+		}
+		wg.Wait()
+
+		if len(deliveries) < deliveryBatchSize {
+			return
+		}
+	}
+}
+
+// Одна попытка доставки. После неудачной попытки следующая назначается через паузу RetryPolicy,
+// после последней доставка считается неуспешной
+func (d *Dispatcher) attempt(delivery repository.WebhookDelivery) {
+	webhook, err := d.store.Webhooks.Get(delivery.WebhookId)
+	if err == sql.ErrNoRows {
+		// Подписка удалена вместе с доставками
+		return
+	} else if err != nil {
+		log.Error(err)
+		return
+	}
+
+	delivery.Attempts++
+	responseCode, err := d.send(webhook, &delivery)
+	delivery.ResponseCode = responseCode
+	var retryIn time.Duration
+	if err == nil {
+		delivery.Status = repository.WebhookDeliveryDelivered
+		delivery.LastError = ""
+	} else {
+		log.Error(err)
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= d.config.Retry.MaxAttempts {
+			delivery.Status = repository.WebhookDeliveryFailed
+		} else {
+			retryIn = d.config.Retry.Backoff(delivery.Attempts)
+		}
+	}
+
+	err = d.store.Webhooks.UpdateDelivery(&delivery, retryIn)
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+// Отправляет одну попытку, успешной считается попытка с ответом 2xx
+func (d *Dispatcher) send(webhook *repository.Webhook, delivery *repository.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	request, err := http.NewRequest(http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+	request.Header.Set(EventHeader, delivery.EventType)
+	request.Header.Set(DeliveryHeader, delivery.Id)
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("Получатель ответил статусом %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
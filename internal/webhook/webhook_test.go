@@ -0,0 +1,246 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"avitoTask/internal/repository"
+	"avitoTask/internal/repository/memory"
+)
+
+const testOrganizationId = "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"
+
+// Запрос, полученный тестовым получателем
+type receivedRequest struct {
+	body      []byte
+	signature string
+	event     string
+	delivery  string
+}
+
+// Получатель, который отвечает 500 на первые failures запросов, а затем 200
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	requests []receivedRequest
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	body, _ := io.ReadAll(request.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, receivedRequest{
+		body:      body,
+		signature: request.Header.Get(SignatureHeader),
+		event:     request.Header.Get(EventHeader),
+		delivery:  request.Header.Get(DeliveryHeader),
+	})
+	if len(r.requests) <= r.failures {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *receiver) received() []receivedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedRequest(nil), r.requests...)
+}
+
+func newTestDispatcher(store *repository.Store) *Dispatcher {
+	return NewDispatcher(store, Config{
+		Retry:               RetryPolicy{MaxAttempts: 3, InitialInterval: 10 * time.Millisecond, MaxInterval: 50 * time.Millisecond},
+		Timeout:             time.Second,
+		PollInterval:        10 * time.Millisecond,
+		AllowPrivateTargets: true,
+	})
+}
+
+// Хранилище с тендером и подпиской организации на адрес target
+func newTestStore(t *testing.T, target string) (*repository.Store, *repository.Tender, *repository.Webhook) {
+	store := memory.NewStore(&memory.Seed{
+		Organizations: []memory.Organization{{Id: testOrganizationId, Name: "Org"}},
+	})
+	tender := &repository.Tender{Name: "Тендер", ServiceType: "Delivery", Status: "Published", OrganizationId: testOrganizationId,
+		Version: 1, CreatedAt: time.Now().Format(time.RFC3339)}
+	err := store.Tenders.Create(tender)
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhook := &repository.Webhook{OrganizationId: testOrganizationId, Url: target, Secret: "secret", CreatedBy: "alice"}
+	err = store.Webhooks.Create(webhook)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, tender, webhook
+}
+
+// Ждет, пока доставка перестанет быть в статусе Pending
+func waitDelivery(t *testing.T, store *repository.Store, webhookId string) repository.WebhookDelivery {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		deliveries, err := store.Webhooks.ListDeliveries(webhookId, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(deliveries) == 1 && deliveries[0].Status != repository.WebhookDeliveryPending {
+			return deliveries[0]
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("доставка не завершена")
+	return repository.WebhookDelivery{}
+}
+
+func TestDeliveryRetriedAfterServerError(t *testing.T) {
+	recv := &receiver{failures: 1}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+	store, tender, webhook := newTestStore(t, server.URL)
+	dispatcher := newTestDispatcher(store)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go dispatcher.Run(ctx)
+
+	payload := `{"type":"tender_closed","tenderId":"` + tender.Id + `"}`
+	var notify func()
+	err := store.InTx(func(tx *repository.Store) error {
+		var err error
+		notify, err = dispatcher.Handle(tx, repository.OutboxEvent{Id: 1, EventType: "tender_closed", TenderId: tender.Id, Payload: payload})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	notify()
+
+	delivery := waitDelivery(t, store, webhook.Id)
+	if delivery.Status != repository.WebhookDeliveryDelivered || delivery.Attempts != 2 || delivery.ResponseCode != http.StatusOK {
+		t.Fatalf("доставка %+v, ожидалась успешная со второй попытки", delivery)
+	}
+
+	requests := recv.received()
+	if len(requests) != 2 {
+		t.Fatalf("получено запросов %d, ожидалось 2", len(requests))
+	}
+	for _, request := range requests {
+		if string(request.body) != payload {
+			t.Errorf("тело запроса %s, ожидалось %s", request.body, payload)
+		}
+		if request.signature != Sign(webhook.Secret, request.body) {
+			t.Errorf("неверная подпись %s", request.signature)
+		}
+		if request.event != "tender_closed" || request.delivery != delivery.Id {
+			t.Errorf("заголовки события %s и доставки %s", request.event, request.delivery)
+		}
+	}
+}
+
+func TestDeliveryFailedAfterMaxAttempts(t *testing.T) {
+	recv := &receiver{failures: 10}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+	store, _, webhook := newTestStore(t, server.URL)
+	err := store.Webhooks.CreateDelivery(&repository.WebhookDelivery{
+		WebhookId: webhook.Id, EventType: "tender_closed", Payload: `{}`, Status: repository.WebhookDeliveryPending,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go newTestDispatcher(store).Run(ctx)
+
+	delivery := waitDelivery(t, store, webhook.Id)
+	if delivery.Status != repository.WebhookDeliveryFailed || delivery.Attempts != 3 ||
+		delivery.ResponseCode != http.StatusInternalServerError || delivery.LastError == "" {
+		t.Fatalf("доставка %+v, ожидалась неуспешная после 3 попыток", delivery)
+	}
+	if len(recv.received()) != 3 {
+		t.Fatalf("получено запросов %d, ожидалось 3", len(recv.received()))
+	}
+}
+
+// Доставка, оставшаяся в статусе Pending после остановки сервиса, отправляется новым обработчиком
+func TestPendingDeliveryResumedAfterRestart(t *testing.T) {
+	recv := &receiver{failures: 1}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+	store, _, webhook := newTestStore(t, server.URL)
+	err := store.Webhooks.CreateDelivery(&repository.WebhookDelivery{
+		WebhookId: webhook.Id, EventType: "tender_closed", Payload: `{}`, Status: repository.WebhookDeliveryPending,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := newTestDispatcher(store)
+	first.deliverDue(ctx)
+	cancel()
+	if len(recv.received()) != 1 {
+		t.Fatalf("получено запросов %d, ожидался 1", len(recv.received()))
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go newTestDispatcher(store).Run(ctx)
+	delivery := waitDelivery(t, store, webhook.Id)
+	if delivery.Status != repository.WebhookDeliveryDelivered || delivery.Attempts != 2 {
+		t.Fatalf("доставка %+v, ожидалась успешная со второй попытки", delivery)
+	}
+}
+
+func TestPrivateTargetsRejected(t *testing.T) {
+	dispatcher := NewDispatcher(nil, Config{Timeout: time.Second})
+	for _, target := range []string{
+		"http://127.0.0.1/hook",
+		"http://localhost:8080/hook",
+		"http://10.0.0.1/hook",
+		"http://192.168.1.10/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		targetUrl, _ := url.Parse(target)
+		if err := dispatcher.CheckTarget(context.Background(), targetUrl); err != ErrTargetNotAllowed {
+			t.Errorf("%s: ошибка %v, ожидалась ErrTargetNotAllowed", target, err)
+		}
+	}
+
+	targetUrl, _ := url.Parse("http://93.184.215.14/hook")
+	if err := dispatcher.CheckTarget(context.Background(), targetUrl); err != nil {
+		t.Errorf("публичный адрес отклонен: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	targetUrl, _ = url.Parse("http://hook.example.com/hook")
+	if err := dispatcher.CheckTarget(ctx, targetUrl); err == nil {
+		t.Error("адрес проверен после отмены запроса")
+	}
+	if err := checkIp(net.ParseIP("8.8.8.8")); err != nil {
+		t.Errorf("публичный адрес отклонен: %v", err)
+	}
+}
+
+// Запрос к частному адресу отклоняется при соединении, даже если подписка создана раньше
+func TestPrivateTargetRejectedOnDial(t *testing.T) {
+	server := httptest.NewServer(&receiver{})
+	defer server.Close()
+	store, _, webhook := newTestStore(t, server.URL)
+	dispatcher := NewDispatcher(store, Config{Retry: RetryPolicy{MaxAttempts: 1}, Timeout: time.Second})
+
+	_, err := dispatcher.send(webhook, &repository.WebhookDelivery{Payload: `{}`})
+	if err == nil {
+		t.Fatal("запрос к loopback-адресу выполнен")
+	}
+}
@@ -0,0 +1,8 @@
+CREATE TABLE bid_feedback
+(
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    bid_id      uuid                                NOT NULL REFERENCES bid (id) ON DELETE CASCADE,
+    username    VARCHAR(50)                         NOT NULL,
+    description VARCHAR(1000)                       NOT NULL,
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1,5 @@
+CREATE TABLE organization_quorum
+(
+    organization_id uuid PRIMARY KEY REFERENCES organization (id) ON DELETE CASCADE,
+    quorum          INTEGER CHECK (quorum >= 1) NOT NULL
+);
\ No newline at end of file
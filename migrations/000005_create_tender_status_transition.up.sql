@@ -0,0 +1,33 @@
+CREATE TABLE tender_status_transition
+(
+    from_status tender_status NOT NULL,
+    to_status   tender_status NOT NULL,
+    PRIMARY KEY (from_status, to_status)
+);
+
+INSERT INTO tender_status_transition (from_status, to_status)
+VALUES ('Created', 'Published'),
+       ('Created', 'Closed'),
+       ('Published', 'Closed');
+
+CREATE OR REPLACE FUNCTION tender_status_transition_trigger_func()
+    RETURNS TRIGGER
+    LANGUAGE 'plpgsql' AS
+$$
+BEGIN
+    IF new.status IS DISTINCT FROM old.status AND NOT EXISTS(SELECT 1
+                                                             FROM tender_status_transition
+                                                             WHERE from_status = old.status
+                                                               AND to_status = new.status) THEN
+        RAISE EXCEPTION 'Недопустимый переход статуса тендера: % -> %', old.status, new.status
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN new;
+END;
+$$;
+
+CREATE TRIGGER check_status_transition
+    BEFORE UPDATE OF status
+    ON tender
+    FOR EACH ROW
+EXECUTE PROCEDURE tender_status_transition_trigger_func();
\ No newline at end of file
@@ -0,0 +1,67 @@
+CREATE TABLE bid_status_transition
+(
+    from_status bid_status NOT NULL,
+    to_status   bid_status NOT NULL,
+    PRIMARY KEY (from_status, to_status)
+);
+
+INSERT INTO bid_status_transition (from_status, to_status)
+VALUES ('Created', 'Published'),
+       ('Created', 'Canceled'),
+       ('Published', 'Canceled');
+
+CREATE OR REPLACE FUNCTION bid_insert_trigger_func()
+    RETURNS TRIGGER
+    LANGUAGE 'plpgsql' AS
+$$
+BEGIN
+    IF NOT EXISTS(SELECT 1 FROM tender WHERE id = new.tender_id AND status = 'Published') THEN
+        RAISE EXCEPTION 'Предложения можно создавать только для опубликованных тендеров'
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN new;
+END;
+$$;
+
+CREATE TRIGGER check_tender_status
+    BEFORE INSERT
+    ON bid
+    FOR EACH ROW
+EXECUTE PROCEDURE bid_insert_trigger_func();
+
+CREATE OR REPLACE FUNCTION bid_lifecycle_trigger_func()
+    RETURNS TRIGGER
+    LANGUAGE 'plpgsql' AS
+$$
+DECLARE
+    tender_status tender_status;
+BEGIN
+    SELECT status INTO tender_status FROM tender WHERE id = old.tender_id;
+
+    IF old.decision IS NOT NULL OR tender_status = 'Closed' THEN
+        RAISE EXCEPTION 'Предложение недоступно для изменения'
+            USING ERRCODE = 'check_violation';
+    END IF;
+
+    IF new.status IS DISTINCT FROM old.status THEN
+        IF NOT EXISTS(SELECT 1
+                      FROM bid_status_transition
+                      WHERE from_status = old.status
+                        AND to_status = new.status) THEN
+            RAISE EXCEPTION 'Недопустимый переход статуса предложения: % -> %', old.status, new.status
+                USING ERRCODE = 'check_violation';
+        END IF;
+        IF new.status = 'Published' AND tender_status <> 'Published' THEN
+            RAISE EXCEPTION 'Предложения можно публиковать только для опубликованных тендеров'
+                USING ERRCODE = 'check_violation';
+        END IF;
+    END IF;
+    RETURN new;
+END;
+$$;
+
+CREATE TRIGGER check_lifecycle
+    BEFORE UPDATE
+    ON bid
+    FOR EACH ROW
+EXECUTE PROCEDURE bid_lifecycle_trigger_func();
\ No newline at end of file
@@ -0,0 +1,25 @@
+CREATE TYPE organization_role_type AS ENUM (
+    'owner',
+    'tender_manager',
+    'approver',
+    'viewer'
+    );
+
+CREATE TABLE organization_employee_role
+(
+    id              uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id uuid                                NOT NULL REFERENCES organization (id) ON DELETE CASCADE,
+    user_id         uuid                                NOT NULL REFERENCES employee (id) ON DELETE CASCADE,
+    role            organization_role_type              NOT NULL,
+    granted_by      VARCHAR(50)                         NOT NULL,
+    granted_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (organization_id, user_id, role)
+);
+
+-- Ответственные за организацию считаются ее владельцами
+CREATE VIEW organization_member_role AS
+SELECT org_r.organization_id, org_r.user_id, 'owner'::organization_role_type AS role
+FROM organization_responsible org_r
+UNION
+SELECT org_er.organization_id, org_er.user_id, org_er.role
+FROM organization_employee_role org_er;
\ No newline at end of file
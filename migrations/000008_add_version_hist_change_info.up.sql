@@ -0,0 +1,80 @@
+CREATE TYPE version_change_kind AS ENUM (
+    'edit',
+    'rollback',
+    'status_change'
+    );
+
+-- Сведения о последнем изменении записывает приложение в том же UPDATE, триггер переносит их в историю
+ALTER TABLE tender
+    ADD COLUMN changed_by     VARCHAR(50),
+    ADD COLUMN change_kind    version_change_kind,
+    ADD COLUMN change_comment VARCHAR(500);
+
+ALTER TABLE tender_version_hist
+    ADD COLUMN changed_by  VARCHAR(50),
+    ADD COLUMN change_kind version_change_kind,
+    ADD COLUMN comment     VARCHAR(500);
+
+ALTER TABLE bid
+    ADD COLUMN changed_by     VARCHAR(50),
+    ADD COLUMN change_kind    version_change_kind,
+    ADD COLUMN change_comment VARCHAR(500);
+
+ALTER TABLE bid_version_hist
+    ADD COLUMN changed_by  VARCHAR(50),
+    ADD COLUMN change_kind version_change_kind,
+    ADD COLUMN comment     VARCHAR(500);
+
+-- Смена статуса тоже создает новую версию. До этой миграции номер версии менялся только при правке
+-- и откате, поэтому после нее номера растут быстрее; уже сохраненная история не перенумеровывается.
+-- Откат восстанавливает только содержимое (название, описание, вид услуги), статус остается текущим.
+-- Если значения полей не изменились, версия не создается и номер не меняется
+CREATE OR REPLACE FUNCTION tender_version_hist_update_trigger_func()
+    RETURNS TRIGGER
+    LANGUAGE 'plpgsql' AS
+$$
+DECLARE
+    params jsonb :='{}'::jsonb;
+BEGIN
+    IF new.name IS DISTINCT FROM old.name OR new.description IS DISTINCT FROM old.description
+        OR new.service_type IS DISTINCT FROM old.service_type OR new.status IS DISTINCT FROM old.status THEN
+
+        params = FORMAT('{"name":"%s"}', old.name)::jsonb ||
+            FORMAT('{"description":"%s"}', old.description)::jsonb ||
+            FORMAT('{"serviceType":"%s"}', old.service_type)::jsonb ||
+            FORMAT('{"status":"%s"}', old.status)::jsonb ||
+            FORMAT('{"organizationId":"%s"}', old.organization_id)::jsonb;
+
+        IF params IS DISTINCT FROM '{}'::jsonb THEN
+            INSERT INTO tender_version_hist (tender_id, version, params, changed_by, change_kind, comment)
+            VALUES (old.id, new.version, params, new.changed_by, new.change_kind, new.change_comment);
+            new.version = new.version + 1;
+        END IF;
+    END IF;
+    RETURN new;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION bid_version_hist_update_trigger_func()
+    RETURNS TRIGGER
+    LANGUAGE 'plpgsql' AS
+$$
+DECLARE
+    params jsonb :='{}'::jsonb;
+BEGIN
+    IF new.name IS DISTINCT FROM old.name OR new.description IS DISTINCT FROM old.description
+        OR new.status IS DISTINCT FROM old.status THEN
+
+        params = FORMAT('{"name":"%s"}', old.name)::jsonb ||
+            FORMAT('{"description":"%s"}', old.description)::jsonb ||
+            FORMAT('{"status":"%s"}', old.status)::jsonb;
+
+        IF params IS DISTINCT FROM '{}'::jsonb THEN
+            INSERT INTO bid_version_hist (bid_id, version, params, changed_by, change_kind, comment)
+            VALUES (old.id, new.version, params, new.changed_by, new.change_kind, new.change_comment);
+            new.version = new.version + 1;
+        END IF;
+    END IF;
+    RETURN new;
+END;
+$$;
\ No newline at end of file
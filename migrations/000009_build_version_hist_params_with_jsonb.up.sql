@@ -0,0 +1,51 @@
+-- Снимок версии собирается через jsonb_build_object: кавычки, обратные слеши и переводы строк
+-- в названии и описании больше не ломают JSON
+CREATE OR REPLACE FUNCTION tender_version_hist_update_trigger_func()
+    RETURNS TRIGGER
+    LANGUAGE 'plpgsql' AS
+$$
+BEGIN
+    IF new.name IS DISTINCT FROM old.name OR new.description IS DISTINCT FROM old.description
+        OR new.service_type IS DISTINCT FROM old.service_type OR new.status IS DISTINCT FROM old.status THEN
+
+        INSERT INTO tender_version_hist (tender_id, version, params, changed_by, change_kind, comment)
+        VALUES (old.id,
+                new.version,
+                jsonb_build_object(
+                        'name', old.name,
+                        'description', old.description,
+                        'serviceType', old.service_type,
+                        'status', old.status,
+                        'organizationId', old.organization_id),
+                new.changed_by,
+                new.change_kind,
+                new.change_comment);
+        new.version = new.version + 1;
+    END IF;
+    RETURN new;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION bid_version_hist_update_trigger_func()
+    RETURNS TRIGGER
+    LANGUAGE 'plpgsql' AS
+$$
+BEGIN
+    IF new.name IS DISTINCT FROM old.name OR new.description IS DISTINCT FROM old.description
+        OR new.status IS DISTINCT FROM old.status THEN
+
+        INSERT INTO bid_version_hist (bid_id, version, params, changed_by, change_kind, comment)
+        VALUES (old.id,
+                new.version,
+                jsonb_build_object(
+                        'name', old.name,
+                        'description', old.description,
+                        'status', old.status),
+                new.changed_by,
+                new.change_kind,
+                new.change_comment);
+        new.version = new.version + 1;
+    END IF;
+    RETURN new;
+END;
+$$;
\ No newline at end of file
@@ -0,0 +1,44 @@
+-- Снимки, записанные через FORMAT, ломались на кавычках в названии или описании: значение обрезалось,
+-- а остаток превращался в лишние ключи или нестроковые значения. Восстановить такие снимки нельзя,
+-- поэтому они не исправляются, а помечаются broken: их можно просмотреть, но нельзя откатиться к ним
+ALTER TABLE tender_version_hist ADD COLUMN broken BOOLEAN DEFAULT false NOT NULL;
+ALTER TABLE bid_version_hist ADD COLUMN broken BOOLEAN DEFAULT false NOT NULL;
+
+UPDATE tender_version_hist
+SET broken = true
+WHERE NOT COALESCE(
+        jsonb_typeof(params) = 'object'
+            AND params - ARRAY ['name', 'description', 'serviceType', 'status', 'organizationId'] = '{}'::jsonb
+            AND jsonb_typeof(params -> 'name') = 'string'
+            AND jsonb_typeof(params -> 'description') = 'string'
+            AND jsonb_typeof(params -> 'serviceType') = 'string'
+            AND jsonb_typeof(params -> 'status') = 'string'
+            AND jsonb_typeof(params -> 'organizationId') = 'string', false);
+
+UPDATE bid_version_hist
+SET broken = true
+WHERE NOT COALESCE(
+        jsonb_typeof(params) = 'object'
+            AND params - ARRAY ['name', 'description', 'status'] = '{}'::jsonb
+            AND jsonb_typeof(params -> 'name') = 'string'
+            AND jsonb_typeof(params -> 'description') = 'string'
+            AND jsonb_typeof(params -> 'status') = 'string', false);
+
+-- Новые снимки должны содержать все поля версии строками
+ALTER TABLE tender_version_hist
+    ADD CONSTRAINT tender_version_hist_params_check CHECK (
+        broken OR (
+            jsonb_typeof(params) = 'object'
+                AND jsonb_typeof(params -> 'name') = 'string'
+                AND jsonb_typeof(params -> 'description') = 'string'
+                AND jsonb_typeof(params -> 'serviceType') = 'string'
+                AND jsonb_typeof(params -> 'status') = 'string'
+                AND jsonb_typeof(params -> 'organizationId') = 'string'));
+
+ALTER TABLE bid_version_hist
+    ADD CONSTRAINT bid_version_hist_params_check CHECK (
+        broken OR (
+            jsonb_typeof(params) = 'object'
+                AND jsonb_typeof(params -> 'name') = 'string'
+                AND jsonb_typeof(params -> 'description') = 'string'
+                AND jsonb_typeof(params -> 'status') = 'string'));
\ No newline at end of file
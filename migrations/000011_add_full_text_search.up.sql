@@ -0,0 +1,20 @@
+-- Поисковые векторы по названию и описанию в русской и английской конфигурациях, совпадения в названии весомее
+ALTER TABLE tender
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', name), 'A') ||
+        setweight(to_tsvector('english', name), 'A') ||
+        setweight(to_tsvector('russian', COALESCE(description, '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+        ) STORED;
+
+CREATE INDEX tender_search_vector_idx ON tender USING GIN (search_vector);
+
+ALTER TABLE bid
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', name), 'A') ||
+        setweight(to_tsvector('english', name), 'A') ||
+        setweight(to_tsvector('russian', COALESCE(description, '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+        ) STORED;
+
+CREATE INDEX bid_search_vector_idx ON bid USING GIN (search_vector);
\ No newline at end of file
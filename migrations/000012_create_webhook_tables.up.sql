@@ -0,0 +1,33 @@
+CREATE TABLE webhook
+(
+    id              uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id uuid                                NOT NULL REFERENCES organization (id) ON DELETE CASCADE,
+    url             VARCHAR(500)                        NOT NULL,
+    secret          VARCHAR(100)                        NOT NULL,
+    -- Пустой массив - подписка на все события
+    event_types     VARCHAR(50)[]                       NOT NULL DEFAULT '{}',
+    created_by      VARCHAR(50)                         NOT NULL,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TYPE webhook_delivery_status AS ENUM (
+    'Pending',
+    'Delivered',
+    'Failed'
+    );
+
+CREATE TABLE webhook_delivery
+(
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id    uuid                                NOT NULL REFERENCES webhook (id) ON DELETE CASCADE,
+    event_type    VARCHAR(50)                         NOT NULL,
+    payload       jsonb                               NOT NULL,
+    status        webhook_delivery_status             NOT NULL DEFAULT 'Pending',
+    attempts      INTEGER                             NOT NULL DEFAULT 0,
+    response_code INTEGER,
+    last_error    TEXT,
+    created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX webhook_delivery_webhook_id_created_at_idx ON webhook_delivery (webhook_id, created_at DESC);
\ No newline at end of file
@@ -0,0 +1,18 @@
+CREATE TABLE outbox_event
+(
+    id         BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(50)                         NOT NULL,
+    tender_id  uuid                                NOT NULL REFERENCES tender (id) ON DELETE CASCADE,
+    payload    jsonb                               NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- События, обработанные получателем. Отметка ставится в одной транзакции с обработкой,
+-- поэтому каждое событие передается каждому получателю один раз
+CREATE TABLE outbox_event_sink
+(
+    sink         VARCHAR(50)                         NOT NULL,
+    event_id     BIGINT                              NOT NULL REFERENCES outbox_event (id) ON DELETE CASCADE,
+    processed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (sink, event_id)
+);
\ No newline at end of file
@@ -0,0 +1,39 @@
+CREATE TABLE audit_log
+(
+    id              BIGSERIAL PRIMARY KEY,
+    actor           VARCHAR(50)                         NOT NULL,
+    action          VARCHAR(50)                         NOT NULL,
+    entity_type     VARCHAR(50)                         NOT NULL,
+    entity_id       VARCHAR(100)                        NOT NULL,
+    -- Организация, администраторы которой видят запись
+    organization_id uuid                                NOT NULL,
+    before          jsonb,
+    after           jsonb,
+    request_id      VARCHAR(100)                        NOT NULL,
+    ip              VARCHAR(50)                         NOT NULL,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX audit_log_organization_id_created_at_idx ON audit_log (organization_id, created_at DESC);
+
+-- Журнал только пополняется: изменение и удаление записей запрещены
+CREATE OR REPLACE FUNCTION audit_log_immutable_trigger_func()
+    RETURNS TRIGGER
+    LANGUAGE 'plpgsql' AS
+$$
+BEGIN
+    RAISE EXCEPTION 'Записи журнала аудита нельзя изменять или удалять';
+END;
+$$;
+
+CREATE TRIGGER check_audit_log_immutable
+    BEFORE UPDATE OR DELETE
+    ON audit_log
+    FOR EACH ROW
+EXECUTE PROCEDURE audit_log_immutable_trigger_func();
+
+CREATE TRIGGER check_audit_log_not_truncated
+    BEFORE TRUNCATE
+    ON audit_log
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE audit_log_immutable_trigger_func();
\ No newline at end of file
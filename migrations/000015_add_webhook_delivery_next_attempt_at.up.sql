@@ -0,0 +1,6 @@
+-- Время следующей попытки доставки в статусе Pending. Доставки выбираются по наступившему времени
+-- следующей попытки, поэтому повторы продолжаются после перезапуска сервиса
+ALTER TABLE webhook_delivery
+    ADD COLUMN next_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL;
+
+CREATE INDEX webhook_delivery_pending_next_attempt_at_idx ON webhook_delivery (next_attempt_at) WHERE status = 'Pending';
\ No newline at end of file